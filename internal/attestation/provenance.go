@@ -0,0 +1,157 @@
+// Package attestation builds and signs the SLSA provenance statement
+// osbuild-composer attaches to a compose once it's done: an in-toto
+// Statement wrapping a SLSA v1.0 provenance predicate that records what
+// built the image, from what blueprint and resolved package set, and
+// signs it the same way a SignJob signs the image itself.
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/osbuild/osbuild-composer/internal/signing/cosign"
+)
+
+// statementType is the in-toto Statement layer version this package
+// produces statements in.
+const statementType = "https://in-toto.io/Statement/v1"
+
+// provenancePredicateType identifies the SLSA provenance predicate that
+// fills in Statement.Predicate below.
+const provenancePredicateType = "https://slsa.dev/provenance/v1"
+
+// builderID identifies osbuild-composer itself as the builder, the way a
+// SLSA verifier expects a stable, human-meaningless identifier rather than
+// a version string.
+const builderID = "https://osbuild.org/osbuild-composer"
+
+// Statement is the in-toto attestation envelope's payload: a subject (what
+// the attestation is about) plus a typed predicate (what's being claimed
+// about it). It's generic over the predicate on the wire, but this package
+// only ever fills in a SLSAProvenancePredicate.
+type Statement struct {
+	Type          string      `json:"_type"`
+	Subject       []Subject   `json:"subject"`
+	PredicateType string      `json:"predicateType"`
+	Predicate     interface{} `json:"predicate"`
+}
+
+// Subject identifies the artifact the statement is about, by digest rather
+// than by name, so the attestation still applies however the artifact ends
+// up being stored or renamed downstream.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// SLSAProvenancePredicate is the SLSA v1.0 provenance predicate: what was
+// built (BuildDefinition) and how the build actually ran (RunDetails).
+type SLSAProvenancePredicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition records what osbuild-composer was asked to build: the
+// manifest it ran, the blueprint it was generated from, and the resolved
+// package set that went into it.
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   ExternalParameters   `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ExternalParameters is the part of BuildDefinition that comes from the
+// caller's request rather than from osbuild-composer's own resolution of
+// it, namely which blueprint was compiled.
+type ExternalParameters struct {
+	Blueprint string `json:"blueprint,omitempty"`
+}
+
+// ResourceDescriptor names one resolved input (here: a depsolved package)
+// by name and content digest, the SLSA way of citing a dependency without
+// trusting its name alone.
+type ResourceDescriptor struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// RunDetails records the specifics of the build that actually ran: which
+// builder ran it and the compose job that identifies the run.
+type RunDetails struct {
+	Builder  Builder  `json:"builder"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Builder identifies who produced the provenance, by a stable ID rather
+// than a version string, matching SLSA's expectation that the ID alone
+// (not its string contents) is what verifiers pin trust to.
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Metadata ties RunDetails back to the compose job that produced it.
+type Metadata struct {
+	InvocationID string `json:"invocationId"`
+}
+
+// NewProvenanceStatement builds the in-toto statement for one image built
+// by jobID from blueprintName, with packages named and digested by the
+// depsolved package set's checksums.
+func NewProvenanceStatement(imageName, imageDigest, jobID, blueprintName string, packages map[string]string) *Statement {
+	deps := make([]ResourceDescriptor, 0, len(packages))
+	for name, digest := range packages {
+		algo, value := splitDigest(digest)
+		deps = append(deps, ResourceDescriptor{
+			Name:   name,
+			Digest: map[string]string{algo: value},
+		})
+	}
+
+	digestAlgo, digestValue := splitDigest(imageDigest)
+
+	return &Statement{
+		Type: statementType,
+		Subject: []Subject{
+			{
+				Name:   imageName,
+				Digest: map[string]string{digestAlgo: digestValue},
+			},
+		},
+		PredicateType: provenancePredicateType,
+		Predicate: SLSAProvenancePredicate{
+			BuildDefinition: BuildDefinition{
+				BuildType: "https://osbuild.org/provenance/compose@v1",
+				ExternalParameters: ExternalParameters{
+					Blueprint: blueprintName,
+				},
+				ResolvedDependencies: deps,
+			},
+			RunDetails: RunDetails{
+				Builder:  Builder{ID: builderID},
+				Metadata: Metadata{InvocationID: jobID},
+			},
+		},
+	}
+}
+
+// Sign marshals stmt and signs it as an in-toto DSSE envelope, the same
+// envelope shape cosign uses for image signatures.
+func Sign(signer cosign.Signer, stmt *Statement) (*cosign.Envelope, error) {
+	payload, err := json.Marshal(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("attestation: marshaling statement: %w", err)
+	}
+
+	return cosign.SignDSSE(signer, cosign.InTotoMediaType, payload)
+}
+
+// splitDigest splits an "algo:hex" digest (e.g. a PackageSpec checksum or
+// an image manifest digest) the way SLSA's digest sets expect it.
+func splitDigest(digest string) (algo, value string) {
+	for i := 0; i < len(digest); i++ {
+		if digest[i] == ':' {
+			return digest[:i], digest[i+1:]
+		}
+	}
+	return "sha256", digest
+}
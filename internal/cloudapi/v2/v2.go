@@ -4,6 +4,7 @@ package v2
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -18,6 +19,7 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/sirupsen/logrus"
 
+	"github.com/osbuild/osbuild-composer/internal/attestation"
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/distro"
@@ -27,7 +29,15 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/ostree"
 	"github.com/osbuild/osbuild-composer/internal/prometheus"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/sbom"
+	"github.com/osbuild/osbuild-composer/internal/signing/cosign"
 	"github.com/osbuild/osbuild-composer/internal/target"
+	"github.com/osbuild/osbuild-composer/internal/uploaders"
+	"github.com/osbuild/osbuild-composer/internal/uploaders/aws"
+	"github.com/osbuild/osbuild-composer/internal/uploaders/awss3"
+	"github.com/osbuild/osbuild-composer/internal/uploaders/azure"
+	"github.com/osbuild/osbuild-composer/internal/uploaders/gcp"
+	"github.com/osbuild/osbuild-composer/internal/uploaders/ociregistry"
 	"github.com/osbuild/osbuild-composer/internal/worker"
 	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
@@ -38,6 +48,19 @@ type Server struct {
 	rpmMetadata rpmmd.RPMMD
 	distros     *distroregistry.Registry
 	awsBucket   string
+	uploaders   *uploaders.Registry
+
+	// signer signs the in-toto provenance attestation GetComposeMetadata
+	// attaches when asked for ?format=in-toto. Left nil (the default)
+	// that format is refused rather than shipping an unsigned attestation.
+	signer cosign.Signer
+}
+
+// SetAttestationSigner configures the signer GetComposeMetadata uses to
+// sign provenance attestations. It's separate from NewServer so a deployment
+// without signing configured doesn't have to pass a nil signer explicitly.
+func (server *Server) SetAttestationSigner(signer cosign.Signer) {
+	server.signer = signer
 }
 
 type apiHandlers struct {
@@ -52,6 +75,13 @@ func NewServer(workers *worker.Server, rpmMetadata rpmmd.RPMMD, distros *distror
 		rpmMetadata: rpmMetadata,
 		distros:     distros,
 		awsBucket:   bucket,
+		uploaders: uploaders.NewRegistry(
+			aws.New(bucket),
+			awss3.New(bucket),
+			gcp.New(),
+			azure.New(),
+			ociregistry.New(),
+		),
 	}
 	return server
 }
@@ -147,13 +177,78 @@ func splitExtension(filename string) string {
 	return "." + strings.Join(filenameParts[1:], ".")
 }
 
+// UploadTarget names one destination of a multi-target image request: the
+// upload destination (matching an uploaders.Uploader's Name(), e.g. "aws",
+// "gcp", "oci-registry") plus that destination's own upload options. Kept
+// hand-written alongside the other UploadOptions variants until the
+// corresponding openapi.v2.yml schema lands.
+type UploadTarget struct {
+	Type          string          `json:"type"`
+	UploadOptions json.RawMessage `json:"upload_options"`
+}
+
+// UploadTypesContainer is the UploadTypes value for the "org.osbuild.container"
+// and "org.osbuild.oci-registry" targets. Kept hand-written alongside
+// UploadTarget until the corresponding openapi.v2.yml schema lands; the
+// other UploadTypes* values come from the generated client instead.
+const UploadTypesContainer UploadTypes = "container"
+
+// ContainerUploadStatus is the UploadStatus.Options variant reported for a
+// container-registry upload. Kept hand-written alongside UploadTarget until
+// the corresponding openapi.v2.yml schema lands.
+type ContainerUploadStatus struct {
+	Registry       string `json:"registry"`
+	Repository     string `json:"repository"`
+	Tag            string `json:"tag"`
+	Digest         string `json:"digest"`
+	ManifestDigest string `json:"manifest_digest"`
+	MediaType      string `json:"media_type"`
+}
+
+// UploadStatusProgress reports a single upload target's transfer progress.
+// Kept hand-written alongside UploadTarget until the corresponding
+// openapi.v2.yml schema lands.
+type UploadStatusProgress struct {
+	BytesUploaded   int64   `json:"bytes_uploaded"`
+	TotalBytes      int64   `json:"total_bytes"`
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+// ImageStatusProgress reports an in-flight OSBuild job's current worker.Phase
+// and, while uploading, how far that phase has gotten. Kept hand-written
+// alongside UploadTarget until the corresponding openapi.v2.yml schema
+// lands.
+type ImageStatusProgress struct {
+	Phase           string  `json:"phase"`
+	PercentComplete float64 `json:"percent_complete,omitempty"`
+}
+
+// The SBOM/attestation error codes below are new ServiceErrorCode values
+// attachMetadataFormat needs that the generated error-code registry
+// (errors.go, not present in this snapshot) hasn't picked up yet. Hand-
+// declared as a stopgap alongside UploadTarget, using placeholder values
+// past the highest Error* code this snapshot references; swap these for
+// the generated constants once that registry gains them.
+const (
+	ErrorFailedToGenerateSBOM        ServiceErrorCode = 1001
+	ErrorAttestationNotConfigured    ServiceErrorCode = 1002
+	ErrorInvalidMetadataFormat       ServiceErrorCode = 1003
+	ErrorFailedToGenerateAttestation ServiceErrorCode = 1004
+)
+
 type imageRequest struct {
 	imageType               distro.ImageType
 	arch                    distro.Arch
 	repositories            []rpmmd.RepoConfig
 	packageSetsRepositories map[string][]rpmmd.RepoConfig
 	imageOptions            distro.ImageOptions
-	target                  *target.Target
+	targets                 []*target.Target
+	// signing and sbomFormat are non-nil/non-empty when the request's
+	// Customizations asked for the produced image to be signed and/or
+	// have an SBOM attached; enqueueCompose/enqueueKojiCompose use them to
+	// enqueue a SignJob per target depending on the OSBuild job.
+	signing    *worker.SigningOptions
+	sbomFormat string
 }
 
 func (h *apiHandlers) PostCompose(ctx echo.Context) error {
@@ -210,6 +305,22 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 		payloadRepositories = *request.Customizations.PayloadRepositories
 	}
 
+	var signingOptions *worker.SigningOptions
+	if request.Customizations != nil && request.Customizations.Signing != nil {
+		signingOptions = &worker.SigningOptions{
+			KeyRef:    request.Customizations.Signing.KeyRef,
+			RekorURL:  request.Customizations.Signing.RekorUrl,
+			FulcioURL: request.Customizations.Signing.FulcioUrl,
+		}
+	}
+
+	var sbomFormat string
+	if request.Customizations != nil && request.Customizations.Sbom != nil && *request.Customizations.Sbom {
+		// SPDX is the default; CycloneDX is opt-in via the same toggle
+		// once the compose request schema grows a format field.
+		sbomFormat = string(sbom.FormatSPDX)
+	}
+
 	// use the same seed for all images so we get the same IDs
 	bigSeed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
 	if err != nil {
@@ -309,148 +420,58 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 			imageOptions.OSTree.Parent = parent
 		}
 
-		var irTarget *target.Target
-		if ir.UploadOptions == nil {
-			// nowhere to put the image, this is a user error
-			if request.Koji == nil {
+		var irTargets []*target.Target
+		switch {
+		case ir.UploadTargets != nil:
+			// A single build can be pushed to more than one destination at
+			// once, e.g. an S3 bucket and an AMI registration.
+			if request.Koji != nil || len(*ir.UploadTargets) == 0 {
 				return HTTPError(ErrorJSONUnMarshallingError)
 			}
-		} else {
-			// TODO: support uploads also for koji
-			if request.Koji != nil {
-				return HTTPError(ErrorJSONUnMarshallingError)
-			}
-			/* oneOf is not supported by the openapi generator so marshal and unmarshal the uploadrequest based on the type */
-			switch ir.ImageType {
-			case ImageTypesAws:
-				fallthrough
-			case ImageTypesAwsRhui:
-				fallthrough
-			case ImageTypesAwsHaRhui:
-				fallthrough
-			case ImageTypesAwsSapRhui:
-				var awsUploadOptions AWSEC2UploadOptions
-				jsonUploadOptions, err := json.Marshal(*ir.UploadOptions)
-				if err != nil {
-					return HTTPError(ErrorJSONMarshallingError)
-				}
-				err = json.Unmarshal(jsonUploadOptions, &awsUploadOptions)
-				if err != nil {
-					return HTTPError(ErrorJSONUnMarshallingError)
-				}
-
-				// For service maintenance, images are discovered by the "Name:composer-api-*"
-				// tag filter. Currently all image names in the service are generated, so they're
-				// guaranteed to be unique as well. If users are ever allowed to name their images,
-				// an extra tag should be added.
-				key := fmt.Sprintf("composer-api-%s", uuid.New().String())
-				t := target.NewAWSTarget(&target.AWSTargetOptions{
-					Filename:          imageType.Filename(),
-					Region:            awsUploadOptions.Region,
-					Bucket:            h.server.awsBucket,
-					Key:               key,
-					ShareWithAccounts: awsUploadOptions.ShareWithAccounts,
-				})
-				if awsUploadOptions.SnapshotName != nil {
-					t.ImageName = *awsUploadOptions.SnapshotName
-				} else {
-					t.ImageName = key
+			for _, ut := range *ir.UploadTargets {
+				uploader, ok := h.server.uploaders.ByName(ut.Type)
+				if !ok {
+					return HTTPError(ErrorUnsupportedImageType)
 				}
 
-				irTarget = t
-			case ImageTypesGuestImage:
-				fallthrough
-			case ImageTypesVsphere:
-				fallthrough
-			case ImageTypesImageInstaller:
-				fallthrough
-			case ImageTypesEdgeInstaller:
-				fallthrough
-			case ImageTypesEdgeContainer:
-				fallthrough
-			case ImageTypesEdgeCommit:
-				var awsS3UploadOptions AWSS3UploadOptions
-				jsonUploadOptions, err := json.Marshal(*ir.UploadOptions)
+				rawUploadOptions, err := json.Marshal(ut.UploadOptions)
 				if err != nil {
 					return HTTPError(ErrorJSONMarshallingError)
 				}
-				err = json.Unmarshal(jsonUploadOptions, &awsS3UploadOptions)
-				if err != nil {
-					return HTTPError(ErrorJSONUnMarshallingError)
-				}
 
-				key := fmt.Sprintf("composer-api-%s", uuid.New().String())
-				t := target.NewAWSS3Target(&target.AWSS3TargetOptions{
-					Filename: imageType.Filename(),
-					Region:   awsS3UploadOptions.Region,
-					Bucket:   h.server.awsBucket,
-					Key:      key,
-				})
-				t.ImageName = key
-
-				irTarget = t
-			case ImageTypesGcp:
-				var gcpUploadOptions GCPUploadOptions
-				jsonUploadOptions, err := json.Marshal(*ir.UploadOptions)
+				t, err := uploader.NewTarget(imageType.Filename(), rawUploadOptions)
 				if err != nil {
-					return HTTPError(ErrorJSONMarshallingError)
-				}
-				err = json.Unmarshal(jsonUploadOptions, &gcpUploadOptions)
-				if err != nil {
-					return HTTPError(ErrorJSONUnMarshallingError)
-				}
-
-				var share []string
-				if gcpUploadOptions.ShareWithAccounts != nil {
-					share = *gcpUploadOptions.ShareWithAccounts
-				}
-
-				object := fmt.Sprintf("composer-api-%s", uuid.New().String())
-				t := target.NewGCPTarget(&target.GCPTargetOptions{
-					Filename:          imageType.Filename(),
-					Region:            gcpUploadOptions.Region,
-					Os:                "", // not exposed in cloudapi for now
-					Bucket:            gcpUploadOptions.Bucket,
-					Object:            object,
-					ShareWithAccounts: share,
-				})
-				// Import will fail if an image with this name already exists
-				if gcpUploadOptions.ImageName != nil {
-					t.ImageName = *gcpUploadOptions.ImageName
-				} else {
-					t.ImageName = object
+					return HTTPErrorWithInternal(ErrorJSONUnMarshallingError, err)
 				}
+				irTargets = append(irTargets, t)
+			}
+		case ir.UploadOptions == nil:
+			// nowhere to put the image, this is a user error
+			if request.Koji == nil {
+				return HTTPError(ErrorJSONUnMarshallingError)
+			}
+		default:
+			// TODO: support uploads also for koji
+			if request.Koji != nil {
+				return HTTPError(ErrorJSONUnMarshallingError)
+			}
+			/* oneOf is not supported by the openapi generator so marshal the uploadrequest and hand it to the uploader registered for this image type */
+			uploader, ok := h.server.uploaders.For(string(ir.ImageType))
+			if !ok {
+				return HTTPError(ErrorUnsupportedImageType)
+			}
 
-				irTarget = t
-			case ImageTypesAzure:
-				var azureUploadOptions AzureUploadOptions
-				jsonUploadOptions, err := json.Marshal(*ir.UploadOptions)
-				if err != nil {
-					return HTTPError(ErrorJSONMarshallingError)
-				}
-				err = json.Unmarshal(jsonUploadOptions, &azureUploadOptions)
-				if err != nil {
-					return HTTPError(ErrorJSONUnMarshallingError)
-				}
-				t := target.NewAzureImageTarget(&target.AzureImageTargetOptions{
-					Filename:       imageType.Filename(),
-					TenantID:       azureUploadOptions.TenantId,
-					Location:       azureUploadOptions.Location,
-					SubscriptionID: azureUploadOptions.SubscriptionId,
-					ResourceGroup:  azureUploadOptions.ResourceGroup,
-				})
-
-				if azureUploadOptions.ImageName != nil {
-					t.ImageName = *azureUploadOptions.ImageName
-				} else {
-					// if ImageName wasn't given, generate a random one
-					t.ImageName = fmt.Sprintf("composer-api-%s", uuid.New().String())
-				}
+			rawUploadOptions, err := json.Marshal(*ir.UploadOptions)
+			if err != nil {
+				return HTTPError(ErrorJSONMarshallingError)
+			}
 
-				irTarget = t
-			default:
-				return HTTPError(ErrorUnsupportedImageType)
+			t, err := uploader.NewTarget(imageType.Filename(), rawUploadOptions)
+			if err != nil {
+				return HTTPErrorWithInternal(ErrorJSONUnMarshallingError, err)
 			}
+
+			irTargets = []*target.Target{t}
 		}
 
 		irs = append(irs, imageRequest{
@@ -459,7 +480,9 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 			repositories:            repositories,
 			imageOptions:            imageOptions,
 			packageSetsRepositories: packageSetsRepositories,
-			target:                  irTarget,
+			targets:                 irTargets,
+			signing:                 signingOptions,
+			sbomFormat:              sbomFormat,
 		})
 	}
 
@@ -513,7 +536,7 @@ func enqueueCompose(workers *worker.Server, distribution distro.Distro, bp bluep
 	}
 
 	id, err = workers.EnqueueOSBuildAsDependency(ir.arch.Name(), &worker.OSBuildJob{
-		Targets: []*target.Target{ir.target},
+		Targets: ir.targets,
 		Exports: ir.imageType.Exports(),
 		PipelineNames: &worker.PipelineNames{
 			Build:   ir.imageType.BuildPipelines(),
@@ -524,12 +547,83 @@ func enqueueCompose(workers *worker.Server, distribution distro.Distro, bp bluep
 		return id, HTTPErrorWithInternal(ErrorEnqueueingJob, err)
 	}
 
+	if err := enqueueSignJobs(workers, ir, id, depsolveJobID); err != nil {
+		return id, err
+	}
+
+	if err := enqueueAzurePathFixJobs(workers, ir, id); err != nil {
+		return id, err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 	go generateManifest(ctx, cancel, workers, depsolveJobID, manifestJobID, ir.imageType, ir.repositories, ir.imageOptions, manifestSeed, bp.Customizations)
 
 	return id, nil
 }
 
+// enqueueSignJobs enqueues one SignJob per ir.targets, each depending on
+// osbuildJobID (so the image is fully uploaded first) and, if an SBOM was
+// requested, also on depsolveJobID (so the worker can attach one). A no-op
+// when neither signing nor an SBOM was requested for ir.
+func enqueueSignJobs(workers *worker.Server, ir imageRequest, osbuildJobID, depsolveJobID uuid.UUID) error {
+	if ir.signing == nil && ir.sbomFormat == "" {
+		return nil
+	}
+
+	var signing worker.SigningOptions
+	if ir.signing != nil {
+		signing = *ir.signing
+	}
+
+	dependencies := []uuid.UUID{osbuildJobID}
+	if ir.sbomFormat != "" {
+		dependencies = append(dependencies, depsolveJobID)
+	}
+
+	for _, t := range ir.targets {
+		_, err := workers.EnqueueSignAsDependency(&worker.SignJob{
+			TargetName: t.Name,
+			Signing:    signing,
+			SBOMFormat: ir.sbomFormat,
+		}, dependencies...)
+		if err != nil {
+			return HTTPErrorWithInternal(ErrorEnqueueingJob, err)
+		}
+	}
+
+	return nil
+}
+
+// defaultAzureStagingContainer is used for the page-blob conversion staging
+// area when an Azure target doesn't configure its own.
+const defaultAzureStagingContainer = "osbuild-page-blob-staging"
+
+// enqueueAzurePathFixJobs enqueues an AzurePathFixJob, depending on
+// osbuildJobID, for every one of ir.targets that uploads to Azure. A no-op
+// if ir has no Azure target.
+func enqueueAzurePathFixJobs(workers *worker.Server, ir imageRequest, osbuildJobID uuid.UUID) error {
+	for _, t := range ir.targets {
+		if t.Name != "org.osbuild.azure.image" {
+			continue
+		}
+
+		stagingContainer := defaultAzureStagingContainer
+		if azureOptions, ok := t.Options.(*target.AzureImageTargetOptions); ok && azureOptions.StagingContainer != "" {
+			stagingContainer = azureOptions.StagingContainer
+		}
+
+		_, err := workers.EnqueueAzurePathFixAsDependency(&worker.AzurePathFixJob{
+			TargetName:       t.Name,
+			StagingContainer: stagingContainer,
+		}, osbuildJobID)
+		if err != nil {
+			return HTTPErrorWithInternal(ErrorEnqueueingJob, err)
+		}
+	}
+
+	return nil
+}
+
 func enqueueKojiCompose(workers *worker.Server, taskID uint64, server, name, version, release string, distribution distro.Distro, bp blueprint.Blueprint, manifestSeed int64, irs []imageRequest) (uuid.UUID, error) {
 	var id uuid.UUID
 	kojiDirectory := "osbuild-composer-koji-" + uuid.New().String()
@@ -611,21 +705,23 @@ func enqueueKojiCompose(workers *worker.Server, taskID uint64, server, name, ver
 func generateManifest(ctx context.Context, cancel context.CancelFunc, workers *worker.Server, depsolveJobID uuid.UUID, manifestJobID uuid.UUID, imageType distro.ImageType, repos []rpmmd.RepoConfig, options distro.ImageOptions, seed int64, b *blueprint.Customizations) {
 	defer cancel()
 
-	// wait until job is in a pending state
+	// Wait until the job is in a pending state, i.e. until its depsolve
+	// dependency has finished. Rather than polling RequestJobById in a
+	// loop, block on a notification that fires as soon as the job becomes
+	// pending, which keeps depsolve-to-manifest latency accurate and
+	// avoids spinning up a timer per in-flight compose.
 	var token uuid.UUID
 	var dynArgs []json.RawMessage
 	var err error
 	for {
 		_, token, _, _, dynArgs, err = workers.RequestJobById(ctx, "", manifestJobID)
 		if err == jobqueue.ErrNotPending {
-			logrus.Debugf("Manifest job %v not pending, waiting for depsolve job to finish", manifestJobID)
-			time.Sleep(time.Millisecond * 50)
 			select {
+			case <-workers.WaitForPending(ctx, manifestJobID):
+				continue
 			case <-ctx.Done():
 				logrus.Warnf("Manifest job %v's dependencies took longer than 5 minutes to finish, returning to avoid dangling routines", manifestJobID)
-				break
-			default:
-				continue
+				return
 			}
 		}
 		if err != nil {
@@ -744,52 +840,20 @@ func (h *apiHandlers) GetComposeStatus(ctx echo.Context, id string) error {
 		}
 
 		var us *UploadStatus
-		if result.TargetResults != nil {
-			// Only single upload target is allowed, therefore only a single upload target result is allowed as well
-			if len(result.TargetResults) != 1 {
-				return HTTPError(ErrorSeveralUploadTargets)
-			}
-			tr := *result.TargetResults[0]
-
-			var uploadType UploadTypes
-			var uploadOptions interface{}
-
-			switch tr.Name {
-			case "org.osbuild.aws":
-				uploadType = UploadTypesAws
-				awsOptions := tr.Options.(*target.AWSTargetResultOptions)
-				uploadOptions = AWSEC2UploadStatus{
-					Ami:    awsOptions.Ami,
-					Region: awsOptions.Region,
-				}
-			case "org.osbuild.aws.s3":
-				uploadType = UploadTypesAwsS3
-				awsOptions := tr.Options.(*target.AWSS3TargetResultOptions)
-				uploadOptions = AWSS3UploadStatus{
-					Url: awsOptions.URL,
-				}
-			case "org.osbuild.gcp":
-				uploadType = UploadTypesGcp
-				gcpOptions := tr.Options.(*target.GCPTargetResultOptions)
-				uploadOptions = GCPUploadStatus{
-					ImageName: gcpOptions.ImageName,
-					ProjectId: gcpOptions.ProjectID,
-				}
-			case "org.osbuild.azure.image":
-				uploadType = UploadTypesAzure
-				gcpOptions := tr.Options.(*target.AzureImageTargetResultOptions)
-				uploadOptions = AzureUploadStatus{
-					ImageName: gcpOptions.ImageName,
+		var uss *[]UploadStatus
+		if len(result.TargetResults) != 0 {
+			statuses := make([]UploadStatus, 0, len(result.TargetResults))
+			for _, tr := range result.TargetResults {
+				s, err := uploadStatusFromTargetResult(tr, result.UploadStatus)
+				if err != nil {
+					return err
 				}
-			default:
-				return HTTPError(ErrorUnknownUploadTarget)
-			}
-
-			us = &UploadStatus{
-				Status:  UploadStatusValue(result.UploadStatus),
-				Type:    uploadType,
-				Options: uploadOptions,
+				statuses = append(statuses, *s)
 			}
+			uss = &statuses
+			// First target kept in the singular field for clients that
+			// only know about one upload target per compose.
+			us = &statuses[0]
 		}
 
 		return ctx.JSON(http.StatusOK, ComposeStatus{
@@ -800,8 +864,10 @@ func (h *apiHandlers) GetComposeStatus(ctx echo.Context, id string) error {
 			},
 			Status: composeStatusFromOSBuildJobStatus(status, &result),
 			ImageStatus: ImageStatus{
-				Status:       imageStatusFromOSBuildJobStatus(status, &result),
-				UploadStatus: us,
+				Status:         imageStatusFromOSBuildJobStatus(status, &result),
+				UploadStatus:   us,
+				UploadStatuses: uss,
+				Progress:       imageStatusProgress(result.Progress),
 			},
 		})
 	} else if jobType == "koji-finalize" {
@@ -828,7 +894,8 @@ func (h *apiHandlers) GetComposeStatus(ctx echo.Context, id string) error {
 			}
 			buildJobResults = append(buildJobResults, buildJobResult)
 			buildJobStatuses = append(buildJobStatuses, ImageStatus{
-				Status: imageStatusFromKojiJobStatus(buildJobStatus, &initResult, &buildJobResult),
+				Status:   imageStatusFromKojiJobStatus(buildJobStatus, &initResult, &buildJobResult),
+				Progress: imageStatusProgress(buildJobResult.Progress),
 			})
 		}
 		response := ComposeStatus{
@@ -852,6 +919,103 @@ func (h *apiHandlers) GetComposeStatus(ctx echo.Context, id string) error {
 	}
 }
 
+// uploadStatusFromTargetResult converts a single target.TargetResult into
+// the UploadStatus the API reports for it. A target-specific failure is
+// reported as a failed UploadStatus rather than failing the whole request,
+// so that one bad destination in a multi-target compose doesn't hide the
+// successful ones.
+func uploadStatusFromTargetResult(tr *target.TargetResult, overallStatus string) (*UploadStatus, error) {
+	status := UploadStatusValue(overallStatus)
+	if tr.TargetError != nil {
+		status = UploadStatusValueFailure
+	}
+
+	var uploadType UploadTypes
+	var uploadOptions interface{}
+
+	switch tr.Name {
+	case "org.osbuild.aws":
+		uploadType = UploadTypesAws
+		if tr.Options != nil {
+			awsOptions := tr.Options.(*target.AWSTargetResultOptions)
+			uploadOptions = AWSEC2UploadStatus{
+				Ami:    awsOptions.Ami,
+				Region: awsOptions.Region,
+			}
+		}
+	case "org.osbuild.aws.s3":
+		uploadType = UploadTypesAwsS3
+		if tr.Options != nil {
+			awsOptions := tr.Options.(*target.AWSS3TargetResultOptions)
+			uploadOptions = AWSS3UploadStatus{
+				Url: awsOptions.URL,
+			}
+		}
+	case "org.osbuild.gcp":
+		uploadType = UploadTypesGcp
+		if tr.Options != nil {
+			gcpOptions := tr.Options.(*target.GCPTargetResultOptions)
+			uploadOptions = GCPUploadStatus{
+				ImageName: gcpOptions.ImageName,
+				ProjectId: gcpOptions.ProjectID,
+			}
+		}
+	case "org.osbuild.azure.image":
+		uploadType = UploadTypesAzure
+		if tr.Options != nil {
+			azureOptions := tr.Options.(*target.AzureImageTargetResultOptions)
+			switch {
+			case azureOptions.PathFixError != "":
+				status = UploadStatusValueFailure
+			case !azureOptions.PathFixApplied:
+				// The upload succeeded but the post-upload page-blob
+				// fixup hasn't reported back yet.
+				status = UploadStatusValueRegistering
+			}
+			uploadOptions = AzureUploadStatus{
+				ImageName:      azureOptions.ImageName,
+				PathFixPhase:   "azure-path-fix",
+				PreFixBlobUrl:  azureOptions.PreFixBlobURL,
+				PostFixBlobUrl: azureOptions.PostFixBlobURL,
+				PathFixError:   azureOptions.PathFixError,
+			}
+		}
+	case "org.osbuild.container", "org.osbuild.oci-registry":
+		uploadType = UploadTypesContainer
+		if tr.Options != nil {
+			ociOptions := tr.Options.(*target.OCIRegistryTargetResultOptions)
+			uploadOptions = ContainerUploadStatus{
+				Registry:       ociOptions.Registry,
+				Repository:     ociOptions.Repository,
+				Tag:            ociOptions.Tag,
+				Digest:         ociOptions.Digest,
+				ManifestDigest: ociOptions.ManifestDigest,
+				MediaType:      ociOptions.MediaType,
+			}
+		}
+	default:
+		return nil, HTTPError(ErrorUnknownUploadTarget)
+	}
+
+	var progress *UploadStatusProgress
+	if tr.TotalBytes > 0 {
+		progress = &UploadStatusProgress{
+			BytesUploaded:   tr.BytesUploaded,
+			TotalBytes:      tr.TotalBytes,
+			PercentComplete: tr.PercentComplete(),
+		}
+	}
+
+	return &UploadStatus{
+		Status:       status,
+		Type:         uploadType,
+		Options:      uploadOptions,
+		SignatureURL: tr.SignatureURL,
+		SBOMURL:      tr.SBOMURL,
+		Progress:     progress,
+	}, nil
+}
+
 func imageStatusFromOSBuildJobStatus(js *worker.JobStatus, result *worker.OSBuildJobResult) ImageStatusValue {
 	if js.Canceled {
 		return ImageStatusValueFailure
@@ -862,9 +1026,7 @@ func imageStatusFromOSBuildJobStatus(js *worker.JobStatus, result *worker.OSBuil
 	}
 
 	if js.Finished.IsZero() {
-		// TODO: handle also ImageStatusValueUploading
-		// TODO: handle also ImageStatusValueRegistering
-		return ImageStatusValueBuilding
+		return imageStatusFromPhase(result.Progress.Phase)
 	}
 
 	if result.Success {
@@ -874,6 +1036,35 @@ func imageStatusFromOSBuildJobStatus(js *worker.JobStatus, result *worker.OSBuil
 	return ImageStatusValueFailure
 }
 
+// imageStatusProgress turns a worker's progress heartbeat into the
+// ImageStatusProgress the API reports, or nil once there's nothing more
+// specific than the coarse ImageStatusValue to show (no heartbeat yet, or
+// the job has already reached a terminal phase).
+func imageStatusProgress(p worker.Progress) *ImageStatusProgress {
+	if p.Phase == "" {
+		return nil
+	}
+	return &ImageStatusProgress{
+		Phase:           string(p.Phase),
+		PercentComplete: p.PercentComplete,
+	}
+}
+
+// imageStatusFromPhase maps the phase a still-running OSBuild job last
+// reported to the ImageStatusValue it should surface as, falling back to
+// the generic "building" spinner for phases that don't have a more
+// specific status (or before the worker has reported one at all).
+func imageStatusFromPhase(phase worker.Phase) ImageStatusValue {
+	switch phase {
+	case worker.PhaseUploading:
+		return ImageStatusValueUploading
+	case worker.PhaseRegistering:
+		return ImageStatusValueRegistering
+	default:
+		return ImageStatusValueBuilding
+	}
+}
+
 func imageStatusFromKojiJobStatus(js *worker.JobStatus, initResult *worker.KojiInitJobResult, buildResult *worker.OSBuildKojiJobResult) ImageStatusValue {
 	if js.Canceled {
 		return ImageStatusValueFailure
@@ -888,7 +1079,7 @@ func imageStatusFromKojiJobStatus(js *worker.JobStatus, initResult *worker.KojiI
 	}
 
 	if js.Finished.IsZero() {
-		return ImageStatusValueBuilding
+		return imageStatusFromPhase(buildResult.Progress.Phase)
 	}
 
 	if buildResult.JobError != nil {
@@ -952,7 +1143,10 @@ func (h *apiHandlers) GetComposeMetadata(ctx echo.Context, id string) error {
 		return HTTPError(ErrorComposeNotFound)
 	}
 
-	// TODO: support koji builds
+	if jobType == "koji-finalize" {
+		return h.getComposeMetadataForKoji(ctx, jobId)
+	}
+
 	if jobType != "osbuild" {
 		return HTTPError(ErrorInvalidJobType)
 	}
@@ -1023,8 +1217,133 @@ func (h *apiHandlers) GetComposeMetadata(ctx echo.Context, id string) error {
 		Packages: &packages,
 	}
 
+	var ostreeCommit string
 	if ostreeCommitMetadata != nil {
-		resp.OstreeCommit = &ostreeCommitMetadata.Compose.OSTreeCommit
+		ostreeCommit = ostreeCommitMetadata.Compose.OSTreeCommit
+		resp.OstreeCommit = &ostreeCommit
+	}
+
+	if format := ctx.QueryParam("format"); format != "" {
+		err := h.attachMetadataFormat(resp, format, jobId.String(), jobId.String(), "", packages, ostreeCommit, func() (distro.Manifest, error) {
+			return osbuildManifest(h.server.workers, jobId)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.JSON(200, resp)
+}
+
+// getComposeMetadataForKoji builds the ComposeMetadata response for a Koji
+// compose by walking KojiFinalizeJobStatus to each per-arch OSBuildKojiJob
+// and merging their RPM/ostree stage metadata into one response, one
+// ComposeMetadataKojiBuild per build, identified by the Koji filename (which
+// already carries the build's NVR and architecture).
+func (h *apiHandlers) getComposeMetadataForKoji(ctx echo.Context, jobId uuid.UUID) error {
+	href := fmt.Sprintf("/api/image-builder-composer/v2/composes/%v/metadata", jobId)
+
+	var finalizeResult worker.KojiFinalizeJobResult
+	finalizeStatus, deps, err := h.server.workers.KojiFinalizeJobStatus(jobId, &finalizeResult)
+	if err != nil {
+		return HTTPErrorWithInternal(ErrorComposeNotFound, err)
+	}
+	if len(deps) < 2 {
+		return HTTPError(ErrorUnexpectedNumberOfImageBuilds)
+	}
+
+	if finalizeStatus.Finished.IsZero() || finalizeStatus.Canceled || finalizeResult.JobError != nil {
+		// finalize job still running, canceled, or failed: empty response
+		return ctx.JSON(200, ComposeMetadata{
+			ObjectReference: ObjectReference{
+				Href: href,
+				Id:   jobId.String(),
+				Kind: "ComposeMetadata",
+			},
+		})
+	}
+
+	var builds []ComposeMetadataKojiBuild
+	var firstBuildJobID uuid.UUID
+	var allPackages []PackageMetadata
+	var sbomOstreeCommit string
+	for i := 1; i < len(deps); i++ {
+		var buildJob worker.OSBuildKojiJob
+		if err := h.server.workers.OSBuildKojiJob(deps[i], &buildJob); err != nil {
+			return HTTPErrorWithInternal(ErrorComposeNotFound, err)
+		}
+
+		var buildResult worker.OSBuildKojiJobResult
+		buildStatus, _, err := h.server.workers.OSBuildKojiJobStatus(deps[i], &buildResult)
+		if err != nil {
+			return HTTPErrorWithInternal(ErrorComposeNotFound, err)
+		}
+		if buildStatus.Canceled || buildResult.JobError != nil || buildResult.OSBuildOutput == nil {
+			// this arch's build didn't produce output: skip it rather than
+			// failing the whole aggregated response for the others
+			continue
+		}
+		if firstBuildJobID == uuid.Nil {
+			firstBuildJobID = deps[i]
+		}
+
+		var ostreeCommitMetadata *osbuild.OSTreeCommitStageMetadata
+		var rpmStagesMd []osbuild.RPMStageMetadata
+		for _, plName := range buildJob.PipelineNames.Payload {
+			plMd, hasMd := buildResult.OSBuildOutput.Metadata[plName]
+			if !hasMd {
+				continue
+			}
+			for _, stageMd := range plMd {
+				switch md := stageMd.(type) {
+				case *osbuild.RPMStageMetadata:
+					rpmStagesMd = append(rpmStagesMd, *md)
+				case *osbuild.OSTreeCommitStageMetadata:
+					ostreeCommitMetadata = md
+				}
+			}
+		}
+
+		buildPackages := stagesToPackageMetadata(rpmStagesMd)
+		build := ComposeMetadataKojiBuild{
+			Filename: buildJob.KojiFilename,
+			Packages: buildPackages,
+		}
+		if ostreeCommitMetadata != nil {
+			commit := ostreeCommitMetadata.Compose.OSTreeCommit
+			build.OstreeCommit = &commit
+			if sbomOstreeCommit == "" {
+				sbomOstreeCommit = commit
+			}
+		}
+		builds = append(builds, build)
+		allPackages = append(allPackages, buildPackages...)
+	}
+
+	resp := &ComposeMetadata{
+		ObjectReference: ObjectReference{
+			Href: href,
+			Id:   jobId.String(),
+			Kind: "ComposeMetadata",
+		},
+		KojiBuilds: &builds,
+	}
+
+	if format := ctx.QueryParam("format"); format != "" {
+		// A Koji compose's builds share one NVR but each have their own
+		// manifest (one per arch); the attestation below covers only the
+		// first build that actually produced output, the same "first kept
+		// in the singular field" compromise GetComposeStatus already makes
+		// for clients that only know about one image per compose.
+		err := h.attachMetadataFormat(resp, format, jobId.String(), jobId.String(), "", allPackages, sbomOstreeCommit, func() (distro.Manifest, error) {
+			if firstBuildJobID == uuid.Nil {
+				return nil, fmt.Errorf("compose %v has no completed build to attest", jobId)
+			}
+			return osbuildKojiManifest(h.server.workers, firstBuildJobID)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	return ctx.JSON(200, resp)
@@ -1051,6 +1370,152 @@ func stagesToPackageMetadata(stages []osbuild.RPMStageMetadata) []PackageMetadat
 	return packages
 }
 
+// packageMetadataToSpecs reshapes the PackageMetadata stagesToPackageMetadata
+// already built back into rpmmd.PackageSpec, the shape sbom.Generate expects,
+// so GetComposeMetadata doesn't have to depsolve again just to attach an SBOM
+// to what it already collected from the build's own RPM stage metadata.
+func packageMetadataToSpecs(packages []PackageMetadata) []rpmmd.PackageSpec {
+	specs := make([]rpmmd.PackageSpec, 0, len(packages))
+	for _, p := range packages {
+		specs = append(specs, rpmmd.PackageSpec{
+			Name:     p.Name,
+			Version:  p.Version,
+			Release:  p.Release,
+			Arch:     p.Arch,
+			Checksum: fmt.Sprintf("md5:%s", p.Sigmd5),
+		})
+	}
+	return specs
+}
+
+// packageDigests reduces packages to the name->digest map a provenance
+// attestation's resolvedDependencies needs, skipping any whose checksum
+// wasn't recorded.
+func packageDigests(packages []PackageMetadata) map[string]string {
+	digests := make(map[string]string, len(packages))
+	for _, p := range packages {
+		if p.Sigmd5 == "" {
+			continue
+		}
+		digests[p.Name] = fmt.Sprintf("md5:%s", p.Sigmd5)
+	}
+	return digests
+}
+
+// manifestDigest hashes manifest's canonical JSON so it can stand in as the
+// SLSA provenance subject digest for an image that, unlike a container,
+// doesn't otherwise have a single well-known content digest.
+func manifestDigest(manifest distro.Manifest) (string, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("marshaling manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("sha256:%x", sum), nil
+}
+
+// osbuildManifest returns the manifest for a non-Koji OSBuildJob, fetching
+// it from the dependency ManifestJob if the build job itself didn't have
+// one embedded (the same fallback GetComposeManifests already used).
+func osbuildManifest(workers *worker.Server, jobId uuid.UUID) (distro.Manifest, error) {
+	var job worker.OSBuildJob
+	if err := workers.OSBuildJob(jobId, &job); err != nil {
+		return nil, err
+	}
+	if len(job.Manifest) != 0 {
+		return job.Manifest, nil
+	}
+
+	_, deps, err := workers.OSBuildJobStatus(jobId, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(deps) < 1 {
+		return nil, fmt.Errorf("job %v has no manifest dependency", jobId)
+	}
+	var manifestResult worker.ManifestJobByIDResult
+	_, _, err = workers.ManifestJobStatus(deps[0], &manifestResult)
+	if err != nil {
+		return nil, err
+	}
+	return manifestResult.Manifest, nil
+}
+
+// osbuildKojiManifest is osbuildManifest's counterpart for one of a Koji
+// compose's per-arch OSBuildKojiJob builds.
+func osbuildKojiManifest(workers *worker.Server, jobId uuid.UUID) (distro.Manifest, error) {
+	var buildJob worker.OSBuildKojiJob
+	if err := workers.OSBuildKojiJob(jobId, &buildJob); err != nil {
+		return nil, err
+	}
+	if len(buildJob.Manifest) != 0 {
+		return buildJob.Manifest, nil
+	}
+
+	_, deps, err := workers.OSBuildKojiJobStatus(jobId, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(deps) < 2 {
+		return nil, fmt.Errorf("job %v has no manifest dependency", jobId)
+	}
+	var manifestResult worker.ManifestJobByIDResult
+	_, _, err = workers.ManifestJobStatus(deps[1], &manifestResult)
+	if err != nil {
+		return nil, err
+	}
+	return manifestResult.Manifest, nil
+}
+
+// attachMetadataFormat fills in resp.SBOM/resp.SBOMFormat or resp.Attestation
+// according to the compose metadata request's ?format= query parameter.
+// An empty format leaves resp untouched, so existing callers that don't pass
+// it see the same response shape the endpoint always returned. blueprintName
+// may be empty: this snapshot's job records don't carry the blueprint back
+// from enqueue time, so the attestation's externalParameters.blueprint is
+// only filled in where that's available.
+func (h *apiHandlers) attachMetadataFormat(resp *ComposeMetadata, format, imageName, invocationID, blueprintName string, packages []PackageMetadata, ostreeCommit string, manifest func() (distro.Manifest, error)) error {
+	switch sbom.Format(format) {
+	case sbom.FormatSPDX, sbom.FormatCycloneDX:
+		doc, _, err := sbom.Generate(sbom.Format(format), imageName, packageMetadataToSpecs(packages), ostreeCommit)
+		if err != nil {
+			return HTTPErrorWithInternal(ErrorFailedToGenerateSBOM, err)
+		}
+		resp.SBOM = json.RawMessage(doc)
+		resp.SBOMFormat = format
+		return nil
+	}
+
+	if format == "" {
+		return nil
+	}
+
+	if format != "in-toto" {
+		return HTTPError(ErrorInvalidMetadataFormat)
+	}
+
+	if h.server.signer == nil {
+		return HTTPError(ErrorAttestationNotConfigured)
+	}
+
+	m, err := manifest()
+	if err != nil {
+		return HTTPErrorWithInternal(ErrorComposeNotFound, err)
+	}
+	digest, err := manifestDigest(m)
+	if err != nil {
+		return HTTPErrorWithInternal(ErrorFailedToGenerateAttestation, err)
+	}
+
+	stmt := attestation.NewProvenanceStatement(imageName, digest, invocationID, blueprintName, packageDigests(packages))
+	envelope, err := attestation.Sign(h.server.signer, stmt)
+	if err != nil {
+		return HTTPErrorWithInternal(ErrorFailedToGenerateAttestation, err)
+	}
+	resp.Attestation = envelope
+	return nil
+}
+
 // Get logs for a compose
 func (h *apiHandlers) GetComposeLogs(ctx echo.Context, id string) error {
 	jobId, err := uuid.Parse(id)
@@ -1063,7 +1528,25 @@ func (h *apiHandlers) GetComposeLogs(ctx echo.Context, id string) error {
 		return HTTPError(ErrorComposeNotFound)
 	}
 
-	// TODO: support non-koji builds
+	if jobType == "osbuild" {
+		var result worker.OSBuildJobResult
+		_, _, err := h.server.workers.OSBuildJobStatus(jobId, &result)
+		if err != nil {
+			return HTTPErrorWithInternal(ErrorComposeNotFound, err)
+		}
+
+		resp := &ComposeLogs{
+			ObjectReference: ObjectReference{
+				Href: fmt.Sprintf("/api/image-builder-composer/v2/composes/%v/logs", jobId),
+				Id:   jobId.String(),
+				Kind: "ComposeLogs",
+			},
+			ImageBuilds: []interface{}{result},
+		}
+
+		return ctx.JSON(http.StatusOK, resp)
+	}
+
 	if jobType != "koji-finalize" {
 		return HTTPError(ErrorInvalidJobType)
 	}
@@ -1121,7 +1604,24 @@ func (h *apiHandlers) GetComposeManifests(ctx echo.Context, id string) error {
 		return HTTPError(ErrorComposeNotFound)
 	}
 
-	// TODO: support non-koji builds
+	if jobType == "osbuild" {
+		manifest, err := osbuildManifest(h.server.workers, jobId)
+		if err != nil {
+			return HTTPErrorWithInternal(ErrorComposeNotFound, err)
+		}
+
+		resp := &ComposeManifests{
+			ObjectReference: ObjectReference{
+				Href: fmt.Sprintf("/api/image-builder-composer/v2/composes/%v/manifests", jobId),
+				Id:   jobId.String(),
+				Kind: "ComposeManifests",
+			},
+			Manifests: []interface{}{manifest},
+		}
+
+		return ctx.JSON(http.StatusOK, resp)
+	}
+
 	if jobType != "koji-finalize" {
 		return HTTPError(ErrorInvalidJobType)
 	}
@@ -0,0 +1,175 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// newTestHandlers wires up a Server backed by a throwaway fsjobqueue
+// instance, the same backend a single-node deployment uses, so the
+// GetComposeMetadata/Logs/Manifests handlers below exercise the real
+// worker.Server plumbing rather than a hand-rolled stand-in.
+func newTestHandlers(t *testing.T) (*apiHandlers, *worker.Server) {
+	t.Helper()
+	jobs, err := fsjobqueue.New(t.TempDir())
+	require.NoError(t, err)
+
+	workers := worker.NewServer(logrus.New(), jobs)
+	return &apiHandlers{server: NewServer(workers, nil, nil, "")}, workers
+}
+
+// finishDependency dequeues jobID (which must already be pending) and
+// finishes it with result, mirroring how generateManifest drives a
+// depsolve/manifest job pair in enqueueCompose.
+func finishDependency(t *testing.T, workers *worker.Server, jobID uuid.UUID, result interface{}) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	select {
+	case <-workers.WaitForPending(ctx, jobID):
+	case <-ctx.Done():
+		t.Fatalf("job %s never became pending", jobID)
+	}
+
+	_, token, _, _, _, err := workers.RequestJobById(ctx, "", jobID)
+	require.NoError(t, err)
+	require.NoError(t, workers.FinishJob(token, result))
+}
+
+// TestGetComposeManifests_SingleImageCloud covers the osbuild branch added
+// to GetComposeManifests: a single-image cloud compose whose OSBuildJob
+// doesn't carry an embedded Manifest, so the manifest must come from its
+// ManifestJobByID dependency instead.
+func TestGetComposeManifests_SingleImageCloud(t *testing.T) {
+	h, workers := newTestHandlers(t)
+
+	depsolveJobID, err := workers.EnqueueDepsolve(&worker.DepsolveJob{})
+	require.NoError(t, err)
+
+	manifestJobID, err := workers.EnqueueManifestJobByID(&worker.ManifestJobByID{}, depsolveJobID)
+	require.NoError(t, err)
+
+	osbuildJobID, err := workers.EnqueueOSBuildAsDependency("x86_64", &worker.OSBuildJob{}, manifestJobID)
+	require.NoError(t, err)
+
+	finishDependency(t, workers, depsolveJobID, &worker.DepsolveJobResult{})
+
+	wantManifest := distro.Manifest(`{"version":"2","pipelines":[]}`)
+	finishDependency(t, workers, manifestJobID, &worker.ManifestJobByIDResult{Manifest: wantManifest})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := echo.New().NewContext(req, rec)
+
+	require.NoError(t, h.GetComposeManifests(ctx, osbuildJobID.String()))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp ComposeManifests
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Manifests, 1)
+
+	gotManifest, err := json.Marshal(resp.Manifests[0])
+	require.NoError(t, err)
+	require.JSONEq(t, string(wantManifest), string(gotManifest))
+}
+
+// TestGetComposeMetadata_SplitArchKoji covers getComposeMetadataForKoji: a
+// two-architecture Koji compose's RPM stage metadata must be aggregated into
+// one ComposeMetadataKojiBuild per arch, keyed by the Koji filename, rather
+// than only the single image GetComposeMetadata handled before.
+func TestGetComposeMetadata_SplitArchKoji(t *testing.T) {
+	h, workers := newTestHandlers(t)
+
+	initID, err := workers.EnqueueKojiInit(&worker.KojiInitJob{
+		Server:  "koji.example.com",
+		Name:    "example",
+		Version: "1",
+		Release: "1",
+	})
+	require.NoError(t, err)
+
+	archPackages := map[string]string{
+		"x86_64":  "kernel",
+		"aarch64": "kernel-aarch64",
+	}
+
+	var buildIDs []uuid.UUID
+	kojiFilenames := make(map[uuid.UUID]string)
+	for arch, pkg := range archPackages {
+		depsolveJobID, err := workers.EnqueueDepsolve(&worker.DepsolveJob{})
+		require.NoError(t, err)
+
+		manifestJobID, err := workers.EnqueueManifestJobByID(&worker.ManifestJobByID{}, depsolveJobID)
+		require.NoError(t, err)
+
+		kojiFilename := "example-1-1." + arch + ".rpm"
+		buildID, err := workers.EnqueueOSBuildKojiAsDependency(arch, &worker.OSBuildKojiJob{
+			PipelineNames: &worker.PipelineNames{Payload: []string{"payload"}},
+			KojiFilename:  kojiFilename,
+		}, manifestJobID, initID)
+		require.NoError(t, err)
+
+		finishDependency(t, workers, depsolveJobID, &worker.DepsolveJobResult{})
+		finishDependency(t, workers, manifestJobID, &worker.ManifestJobByIDResult{Manifest: distro.Manifest(`{}`)})
+		finishDependency(t, workers, buildID, &worker.OSBuildKojiJobResult{
+			OSBuildOutput: &osbuild.Result{
+				Metadata: osbuild.BuildMetadata{
+					"payload": []osbuild.StageMetadata{
+						&osbuild.RPMStageMetadata{
+							Packages: []osbuild.RPMPackageMetadata{
+								{Name: pkg, Version: "1.0", Release: "1.fc38", Arch: arch},
+							},
+						},
+					},
+				},
+			},
+		})
+
+		buildIDs = append(buildIDs, buildID)
+		kojiFilenames[buildID] = kojiFilename
+	}
+
+	finalizeID, err := workers.EnqueueKojiFinalize(&worker.KojiFinalizeJob{
+		Server:  "koji.example.com",
+		Name:    "example",
+		Version: "1",
+		Release: "1",
+	}, initID, buildIDs)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := echo.New().NewContext(req, rec)
+
+	require.NoError(t, h.GetComposeMetadata(ctx, finalizeID.String()))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp ComposeMetadata
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotNil(t, resp.KojiBuilds)
+	require.Len(t, *resp.KojiBuilds, len(archPackages))
+
+	gotFilenames := make(map[string]bool)
+	for _, build := range *resp.KojiBuilds {
+		gotFilenames[build.Filename] = true
+		require.Len(t, build.Packages, 1)
+	}
+	for _, buildID := range buildIDs {
+		require.True(t, gotFilenames[kojiFilenames[buildID]])
+	}
+}
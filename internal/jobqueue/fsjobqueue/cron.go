@@ -0,0 +1,83 @@
+package fsjobqueue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a parsed 5-field cron expression (minute hour dom month dow),
+// each field a set of accepted values. It supports the subset of syntax the
+// queue's own schedules actually need: "*", "*/step", comma lists, ranges,
+// and plain numbers — enough for EnqueuePeriodic without pulling in a cron
+// library this tree has no module file to vendor.
+type cronSpec struct {
+	minute, hour, dom, month, dow map[int]bool
+}
+
+func parseCron(expr string) (*cronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("fsjobqueue: cron expression %q must have 5 fields", expr)
+	}
+
+	ranges := []struct{ min, max int }{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	sets := make([]map[int]bool, 5)
+	for i, field := range fields {
+		set, err := parseCronField(field, ranges[i].min, ranges[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("fsjobqueue: cron expression %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+
+	return &cronSpec{minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4]}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		base := part
+		step := 1
+		if idx := strings.Index(part, "/"); idx != -1 {
+			base = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case base == "*":
+			// lo/hi already cover the whole range
+		case strings.Contains(base, "-"):
+			bounds := strings.SplitN(base, "-", 2)
+			a, err1 := strconv.Atoi(bounds[0])
+			b, err2 := strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid range %q", base)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(base)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", base)
+			}
+			lo, hi = n, n
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matches reports whether t (evaluated in spec.TimeZone, handled by the
+// caller) falls on a tick of this schedule, at minute granularity.
+func (c *cronSpec) matches(t time.Time) bool {
+	return c.minute[t.Minute()] && c.hour[t.Hour()] && c.dom[t.Day()] && c.month[int(t.Month())] && c.dow[int(t.Weekday())]
+}
@@ -0,0 +1,815 @@
+// Package fsjobqueue provides an in-memory implementation of
+// jobqueue.JobQueue, used by single-node deployments and by tests via
+// jobqueuetest.TestJobQueue. Unlike the Postgres-backed queue, it keeps no
+// state on disk: baseDir is accepted (and validated as a writable
+// directory) purely so callers have a stable place to point a future
+// on-disk persistence layer at, without changing this package's API.
+package fsjobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+type jobState struct {
+	id                uuid.UUID
+	jobType           string
+	args              json.RawMessage
+	dependencies      []uuid.UUID
+	dependents        []uuid.UUID
+	requiredArtifacts []string
+	priority          int
+	tenantID          string
+	parent            uuid.UUID
+
+	queued   time.Time
+	started  time.Time
+	finished time.Time
+	canceled bool
+
+	token         uuid.UUID
+	lastHeartbeat time.Time
+	cancelCh      chan struct{}
+
+	result json.RawMessage
+
+	attempts  int
+	lastError string
+
+	readyCh     chan struct{}
+	readyClosed bool
+}
+
+type workerState struct {
+	capabilities []string
+	cache        map[string]bool
+}
+
+type periodicDef struct {
+	id           uuid.UUID
+	spec         jobqueue.PeriodicSpec
+	jobType      string
+	argsTemplate interface{}
+	cron         *cronSpec
+	lastTick     time.Time
+	children     []uuid.UUID
+}
+
+type parameterizedDef struct {
+	id         uuid.UUID
+	jobType    string
+	metaSchema json.RawMessage
+	children   []uuid.UUID
+}
+
+// Queue is an in-memory jobqueue.JobQueue. Use New or NewWithConfig to
+// construct one.
+type Queue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	baseDir string
+	config  jobqueue.Config
+	clock   jobqueue.Clock
+
+	jobs       map[uuid.UUID]*jobState
+	tokens     map[uuid.UUID]uuid.UUID
+	workers    map[uuid.UUID]*workerState
+	periodics  map[uuid.UUID]*periodicDef
+	parameters map[uuid.UUID]*parameterizedDef
+
+	stopScheduler chan struct{}
+}
+
+// New creates a Queue with default scheduling (no priority bands, no
+// per-tenant concurrency cap) rooted at baseDir.
+func New(baseDir string) (*Queue, error) {
+	return NewWithConfig(baseDir, jobqueue.Config{})
+}
+
+// NewWithConfig creates a Queue tuned by cfg's priority/fair-share knobs.
+func NewWithConfig(baseDir string, cfg jobqueue.Config) (*Queue, error) {
+	if baseDir != "" {
+		if info, err := os.Stat(baseDir); err != nil {
+			return nil, fmt.Errorf("fsjobqueue: %w", err)
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("fsjobqueue: %s is not a directory", baseDir)
+		}
+	}
+
+	q := &Queue{
+		baseDir:       baseDir,
+		config:        cfg,
+		clock:         jobqueue.RealClock,
+		jobs:          make(map[uuid.UUID]*jobState),
+		tokens:        make(map[uuid.UUID]uuid.UUID),
+		workers:       make(map[uuid.UUID]*workerState),
+		periodics:     make(map[uuid.UUID]*periodicDef),
+		parameters:    make(map[uuid.UUID]*parameterizedDef),
+		stopScheduler: make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	go q.runScheduler()
+
+	return q, nil
+}
+
+// Stop shuts down the periodic-schedule background goroutine. Call it
+// exactly once when done with the queue (e.g. as the stop func returned to
+// jobqueuetest.TestJobQueue).
+func (q *Queue) Stop() {
+	close(q.stopScheduler)
+}
+
+func (q *Queue) SetClock(clock jobqueue.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.clock = clock
+}
+
+func (q *Queue) now() time.Time {
+	return q.clock.Now()
+}
+
+// Enqueue implements jobqueue.JobQueue.
+func (q *Queue) Enqueue(jobType string, args interface{}, dependencies []uuid.UUID, requiredArtifacts []string, priority int, tenantID string) (uuid.UUID, error) {
+	rawArgs, err := json.Marshal(args)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("fsjobqueue: cannot marshal args: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, dep := range dependencies {
+		if _, ok := q.jobs[dep]; !ok {
+			return uuid.Nil, fmt.Errorf("fsjobqueue: dependency %s does not exist", dep)
+		}
+	}
+
+	j := &jobState{
+		id:                uuid.New(),
+		jobType:           jobType,
+		args:              rawArgs,
+		dependencies:      append([]uuid.UUID(nil), dependencies...),
+		requiredArtifacts: append([]string(nil), requiredArtifacts...),
+		priority:          priority,
+		tenantID:          tenantID,
+		queued:            q.now(),
+		readyCh:           make(chan struct{}),
+	}
+
+	q.jobs[j.id] = j
+	for _, dep := range dependencies {
+		depJob := q.jobs[dep]
+		depJob.dependents = append(depJob.dependents, j.id)
+	}
+
+	q.maybeMarkReady(j)
+	q.cond.Broadcast()
+
+	return j.id, nil
+}
+
+// depsSatisfied reports whether every dependency of j has finished without
+// being canceled.
+func (q *Queue) depsSatisfied(j *jobState) bool {
+	for _, dep := range j.dependencies {
+		depJob, ok := q.jobs[dep]
+		if !ok || depJob.finished.IsZero() || depJob.canceled {
+			return false
+		}
+	}
+	return true
+}
+
+// maybeMarkReady closes j.readyCh (Notify's signal) once j can never block
+// on anything else: either its dependencies are all satisfied, or it was
+// canceled before that ever happened.
+func (q *Queue) maybeMarkReady(j *jobState) {
+	if j.readyClosed {
+		return
+	}
+	if j.canceled || q.depsSatisfied(j) {
+		close(j.readyCh)
+		j.readyClosed = true
+	}
+}
+
+// Dequeue implements jobqueue.JobQueue.
+func (q *Queue) Dequeue(ctx context.Context, workerID uuid.UUID, jobTypes []string) (uuid.UUID, uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.cond.Broadcast()
+			q.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	for {
+		if j := q.pickReadyJob(workerID, jobTypes); j != nil {
+			return q.dequeueJob(j)
+		}
+
+		select {
+		case <-ctx.Done():
+			return uuid.Nil, uuid.Nil, nil, "", nil, jobqueue.ErrDequeueTimeout
+		default:
+		}
+
+		q.cond.Wait()
+	}
+}
+
+func (q *Queue) dequeueJob(j *jobState) (uuid.UUID, uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+	j.token = uuid.New()
+	j.started = q.now()
+	j.lastHeartbeat = j.started
+	j.cancelCh = make(chan struct{})
+	if j.canceled {
+		close(j.cancelCh)
+	}
+	q.tokens[j.token] = j.id
+	return j.id, j.token, append([]uuid.UUID(nil), j.dependencies...), j.jobType, j.args, nil
+}
+
+// pickReadyJob applies the priority/fair-share/locality policy documented
+// on jobqueue.JobQueue to the set of jobs ready to run, or returns nil if
+// none are.
+func (q *Queue) pickReadyJob(workerID uuid.UUID, jobTypes []string) *jobState {
+	wantedTypes := make(map[string]bool, len(jobTypes))
+	for _, t := range jobTypes {
+		wantedTypes[t] = true
+	}
+
+	var candidates []*jobState
+	for _, j := range q.jobs {
+		if wantedTypes[j.jobType] && j.token == uuid.Nil && j.finished.IsZero() && !j.canceled && q.depsSatisfied(j) {
+			candidates = append(candidates, j)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	bandOf := func(j *jobState) int {
+		if q.config.PriorityBands <= 0 {
+			return 0
+		}
+		b := j.priority
+		if b < 0 {
+			b = 0
+		}
+		if b >= q.config.PriorityBands {
+			b = q.config.PriorityBands - 1
+		}
+		return b
+	}
+
+	maxBand := bandOf(candidates[0])
+	for _, j := range candidates[1:] {
+		if b := bandOf(j); b > maxBand {
+			maxBand = b
+		}
+	}
+
+	for band := maxBand; band >= 0; band-- {
+		var inBand []*jobState
+		for _, j := range candidates {
+			if bandOf(j) == band {
+				inBand = append(inBand, j)
+			}
+		}
+		if len(inBand) == 0 {
+			continue
+		}
+
+		pool := q.filterByTenantFairness(inBand)
+		if len(pool) == 0 {
+			continue
+		}
+
+		return q.pickByLocality(pool, workerID)
+	}
+
+	return nil
+}
+
+// filterByTenantFairness narrows candidates (all in the same priority
+// band) to the tenant with the fewest currently-running jobs, skipping any
+// tenant already at Config.MaxConcurrentPerTenant — unless every tenant
+// with ready work in this band is at its cap, in which case the cap is
+// ignored for this pick. Without that fallback a single over-cap tenant
+// with no other tenant's work to dequeue instead would stall the queue
+// entirely rather than being a fairness trade-off against busier tenants.
+func (q *Queue) filterByTenantFairness(candidates []*jobState) []*jobState {
+	byTenant := make(map[string][]*jobState)
+	for _, j := range candidates {
+		byTenant[j.tenantID] = append(byTenant[j.tenantID], j)
+	}
+
+	runningCount := func(tenantID string) int {
+		n := 0
+		for _, j := range q.jobs {
+			if j.tenantID == tenantID && !j.started.IsZero() && j.finished.IsZero() && !j.canceled {
+				n++
+			}
+		}
+		return n
+	}
+
+	var bestTenant string
+	bestRunning := -1
+	haveTenant := false
+	for tenantID := range byTenant {
+		running := runningCount(tenantID)
+		if q.config.MaxConcurrentPerTenant > 0 && running >= q.config.MaxConcurrentPerTenant {
+			continue
+		}
+		if !haveTenant || running < bestRunning {
+			bestTenant, bestRunning, haveTenant = tenantID, running, true
+		}
+	}
+	if !haveTenant {
+		// Every tenant with ready work is at its cap: picking none would
+		// starve the queue, so fall back to plain fewest-running-first
+		// across all of them instead of enforcing the cap.
+		for tenantID := range byTenant {
+			running := runningCount(tenantID)
+			if !haveTenant || running < bestRunning {
+				bestTenant, bestRunning, haveTenant = tenantID, running, true
+			}
+		}
+	}
+
+	return byTenant[bestTenant]
+}
+
+// pickByLocality prefers the oldest job among those whose RequiredArtifacts
+// intersect workerID's reported cache, falling back to the oldest job
+// overall.
+func (q *Queue) pickByLocality(pool []*jobState, workerID uuid.UUID) *jobState {
+	var cache map[string]bool
+	if w, ok := q.workers[workerID]; ok {
+		cache = w.cache
+	}
+
+	var local, rest []*jobState
+	for _, j := range pool {
+		if len(j.requiredArtifacts) > 0 && cacheIntersects(cache, j.requiredArtifacts) {
+			local = append(local, j)
+		} else {
+			rest = append(rest, j)
+		}
+	}
+
+	pick := local
+	if len(pick) == 0 {
+		pick = rest
+	}
+
+	oldest := pick[0]
+	for _, j := range pick[1:] {
+		if j.queued.Before(oldest.queued) {
+			oldest = j
+		}
+	}
+	return oldest
+}
+
+func cacheIntersects(cache map[string]bool, artifacts []string) bool {
+	for _, a := range artifacts {
+		if cache[a] {
+			return true
+		}
+	}
+	return false
+}
+
+// DequeueByID implements jobqueue.JobQueue.
+func (q *Queue) DequeueByID(ctx context.Context, id uuid.UUID) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return uuid.Nil, nil, "", nil, jobqueue.ErrNotExist
+	}
+	if j.token != uuid.Nil || !j.finished.IsZero() || j.canceled || !q.depsSatisfied(j) {
+		return uuid.Nil, nil, "", nil, jobqueue.ErrNotPending
+	}
+
+	_, token, deps, jobType, args, _ := q.dequeueJob(j)
+	return token, deps, jobType, args, nil
+}
+
+// FinishJob implements jobqueue.JobQueue.
+func (q *Queue) FinishJob(id uuid.UUID, result interface{}) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return jobqueue.ErrNotExist
+	}
+	if j.canceled {
+		return jobqueue.ErrCanceled
+	}
+
+	var rawResult json.RawMessage
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("fsjobqueue: cannot marshal result: %w", err)
+		}
+		rawResult = data
+	}
+
+	j.result = rawResult
+	j.finished = q.now()
+	delete(q.tokens, j.token)
+	j.token = uuid.Nil
+
+	for _, depID := range j.dependents {
+		if dep, ok := q.jobs[depID]; ok {
+			q.maybeMarkReady(dep)
+		}
+	}
+
+	q.cond.Broadcast()
+	return nil
+}
+
+// CancelJob implements jobqueue.JobQueue.
+func (q *Queue) CancelJob(id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return jobqueue.ErrNotExist
+	}
+	if !j.finished.IsZero() {
+		return jobqueue.ErrNotRunning
+	}
+	if j.canceled {
+		return nil
+	}
+
+	q.cancelCascade(j)
+	q.cond.Broadcast()
+	return nil
+}
+
+// cancelCascade marks j and every not-yet-finished transitive dependent as
+// canceled, closing each running one's cancelCh so WatchCancel fires.
+func (q *Queue) cancelCascade(j *jobState) {
+	if j.canceled || !j.finished.IsZero() {
+		return
+	}
+	j.canceled = true
+	q.maybeMarkReady(j)
+	if j.token != uuid.Nil && j.cancelCh != nil {
+		select {
+		case <-j.cancelCh:
+		default:
+			close(j.cancelCh)
+		}
+	}
+
+	for _, depID := range j.dependents {
+		if dep, ok := q.jobs[depID]; ok {
+			q.cancelCascade(dep)
+		}
+	}
+}
+
+// WatchCancel implements jobqueue.JobQueue.
+func (q *Queue) WatchCancel(token uuid.UUID) <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id, ok := q.tokens[token]
+	if !ok {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	j := q.jobs[id]
+	if j.canceled && j.cancelCh == nil {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	return j.cancelCh
+}
+
+// JobStatus implements jobqueue.JobQueue.
+func (q *Queue) JobStatus(id uuid.UUID) (string, json.RawMessage, time.Time, time.Time, time.Time, bool, []uuid.UUID, uuid.UUID, int, string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return "", nil, time.Time{}, time.Time{}, time.Time{}, false, nil, uuid.Nil, 0, "", jobqueue.ErrNotExist
+	}
+
+	return j.jobType, j.result, j.queued, j.started, j.finished, j.canceled, append([]uuid.UUID(nil), j.dependencies...), j.parent, j.attempts, j.lastError, nil
+}
+
+// Job implements jobqueue.JobQueue.
+func (q *Queue) Job(id uuid.UUID) (string, json.RawMessage, []uuid.UUID, int, string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return "", nil, nil, 0, "", jobqueue.ErrNotExist
+	}
+
+	return j.jobType, j.args, append([]uuid.UUID(nil), j.dependencies...), j.attempts, j.lastError, nil
+}
+
+// IdFromToken implements jobqueue.JobQueue.
+func (q *Queue) IdFromToken(token uuid.UUID) (uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id, ok := q.tokens[token]
+	if !ok {
+		return uuid.Nil, jobqueue.ErrNotExist
+	}
+	return id, nil
+}
+
+// Heartbeats implements jobqueue.JobQueue.
+func (q *Queue) Heartbeats(olderThan time.Duration) []uuid.UUID {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var tokens []uuid.UUID
+	for token, id := range q.tokens {
+		j := q.jobs[id]
+		if now.Sub(j.lastHeartbeat) >= olderThan {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// RequeueStale implements jobqueue.JobQueue.
+func (q *Queue) RequeueStale(olderThan time.Duration, maxAttempts int) ([]uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var requeued []uuid.UUID
+	for token, id := range q.tokens {
+		j := q.jobs[id]
+		if now.Sub(j.lastHeartbeat) < olderThan {
+			continue
+		}
+
+		j.attempts++
+		j.lastError = jobqueue.ErrWorkerLost.Error()
+		delete(q.tokens, token)
+
+		if j.attempts < maxAttempts {
+			j.token = uuid.Nil
+			j.started = time.Time{}
+			j.cancelCh = nil
+			requeued = append(requeued, id)
+		} else {
+			j.token = uuid.Nil
+			j.finished = now
+			j.result = nil
+			for _, depID := range j.dependents {
+				if dep, ok := q.jobs[depID]; ok {
+					q.maybeMarkReady(dep)
+				}
+			}
+		}
+	}
+
+	q.cond.Broadcast()
+	return requeued, nil
+}
+
+// Notify implements jobqueue.JobQueue.
+func (q *Queue) Notify(job uuid.UUID) <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[job]
+	if !ok {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	return j.readyCh
+}
+
+// RegisterWorker implements jobqueue.JobQueue.
+func (q *Queue) RegisterWorker(capabilities []string) (uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := uuid.New()
+	q.workers[id] = &workerState{
+		capabilities: append([]string(nil), capabilities...),
+		cache:        make(map[string]bool),
+	}
+	return id, nil
+}
+
+// WorkerCacheUpdate implements jobqueue.JobQueue.
+func (q *Queue) WorkerCacheUpdate(workerID uuid.UUID, added, removed []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	w, ok := q.workers[workerID]
+	if !ok {
+		return jobqueue.ErrWorkerNotExist
+	}
+	for _, a := range added {
+		w.cache[a] = true
+	}
+	for _, r := range removed {
+		delete(w.cache, r)
+	}
+	return nil
+}
+
+// EnqueuePeriodic implements jobqueue.JobQueue.
+func (q *Queue) EnqueuePeriodic(spec jobqueue.PeriodicSpec, jobType string, argsTemplate interface{}) (uuid.UUID, error) {
+	cron, err := parseCron(spec.Cron)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	def := &periodicDef{
+		id:           uuid.New(),
+		spec:         spec,
+		jobType:      jobType,
+		argsTemplate: argsTemplate,
+		cron:         cron,
+		lastTick:     q.now().Truncate(time.Minute),
+	}
+	q.periodics[def.id] = def
+	return def.id, nil
+}
+
+// EnqueueParameterized implements jobqueue.JobQueue.
+func (q *Queue) EnqueueParameterized(jobType string, metaSchema json.RawMessage) (uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	def := &parameterizedDef{
+		id:         uuid.New(),
+		jobType:    jobType,
+		metaSchema: metaSchema,
+	}
+	q.parameters[def.id] = def
+	return def.id, nil
+}
+
+// Dispatch implements jobqueue.JobQueue.
+func (q *Queue) Dispatch(parentID uuid.UUID, metaOverrides interface{}, payload interface{}) (uuid.UUID, error) {
+	rawArgs, err := json.Marshal(payload)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("fsjobqueue: cannot marshal payload: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	def, ok := q.parameters[parentID]
+	if !ok {
+		return uuid.Nil, jobqueue.ErrNotParent
+	}
+
+	child := &jobState{
+		id:      uuid.New(),
+		jobType: def.jobType,
+		args:    rawArgs,
+		parent:  parentID,
+		queued:  q.now(),
+		readyCh: make(chan struct{}),
+	}
+	q.jobs[child.id] = child
+	def.children = append(def.children, child.id)
+	q.maybeMarkReady(child)
+	q.cond.Broadcast()
+
+	return child.id, nil
+}
+
+// ChildJobs implements jobqueue.JobQueue.
+func (q *Queue) ChildJobs(parentID uuid.UUID) ([]uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if def, ok := q.periodics[parentID]; ok {
+		return append([]uuid.UUID(nil), def.children...), nil
+	}
+	if def, ok := q.parameters[parentID]; ok {
+		return append([]uuid.UUID(nil), def.children...), nil
+	}
+	return nil, jobqueue.ErrNotParent
+}
+
+// runScheduler polls every periodic definition's schedule at a short,
+// fixed real-world interval. Polling (rather than sleeping until the next
+// tick) is what lets SetClock's fake clock drive it deterministically in
+// tests without waiting out real cron intervals.
+func (q *Queue) runScheduler() {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stopScheduler:
+			return
+		case <-ticker.C:
+			q.tickPeriodics()
+		}
+	}
+}
+
+func (q *Queue) tickPeriodics() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, def := range q.periodics {
+		loc := time.UTC
+		if def.spec.TimeZone != "" {
+			if l, err := time.LoadLocation(def.spec.TimeZone); err == nil {
+				loc = l
+			}
+		}
+
+		cur := q.now().In(loc)
+		curMinute := cur.Truncate(time.Minute)
+		if !curMinute.After(def.lastTick) {
+			continue
+		}
+		def.lastTick = curMinute
+
+		if !def.cron.matches(curMinute) {
+			continue
+		}
+
+		if def.spec.ProhibitOverlap && q.hasOpenChild(def.children) {
+			continue
+		}
+
+		rawArgs, err := json.Marshal(def.argsTemplate)
+		if err != nil {
+			continue
+		}
+		child := &jobState{
+			id:      uuid.New(),
+			jobType: def.jobType,
+			args:    rawArgs,
+			parent:  def.id,
+			queued:  q.now(),
+			readyCh: make(chan struct{}),
+		}
+		q.jobs[child.id] = child
+		def.children = append(def.children, child.id)
+		q.maybeMarkReady(child)
+		q.cond.Broadcast()
+	}
+}
+
+func (q *Queue) hasOpenChild(children []uuid.UUID) bool {
+	for _, id := range children {
+		if j, ok := q.jobs[id]; ok && j.finished.IsZero() && !j.canceled {
+			return true
+		}
+	}
+	return false
+}
+
+var _ jobqueue.JobQueue = (*Queue)(nil)
+var _ jobqueue.ClockSetter = (*Queue)(nil)
@@ -0,0 +1,21 @@
+package fsjobqueue
+
+import (
+	"testing"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/jobqueuetest"
+)
+
+func TestJobQueueConformance(t *testing.T) {
+	jobqueuetest.TestJobQueue(t, func() (jobqueue.JobQueue, func(), error) {
+		q, err := NewWithConfig(t.TempDir(), jobqueue.Config{
+			MaxConcurrentPerTenant: 1,
+			PriorityBands:          2,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return q, q.Stop, nil
+	})
+}
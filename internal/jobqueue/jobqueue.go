@@ -0,0 +1,267 @@
+// Package jobqueue defines the JobQueue interface implemented by this
+// project's job queue backends: fsjobqueue, the in-memory queue used by
+// tests and single-node deployments, and (not yet present in this tree) a
+// Postgres-backed queue for production, which would drive Notify and
+// Dequeue's blocking wait off LISTEN/NOTIFY instead of fsjobqueue's
+// in-process condition variable. See jobqueuetest for the shared
+// conformance test suite every implementation must pass.
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotExist       = errors.New("job does not exist")
+	ErrNotPending     = errors.New("job is not pending")
+	ErrNotRunning     = errors.New("job is not running")
+	ErrDequeueTimeout = errors.New("dequeue timed out")
+	ErrWorkerNotExist = errors.New("worker does not exist")
+	ErrNotParent      = errors.New("job is not a periodic or parameterized definition")
+	ErrCanceled       = errors.New("job was canceled")
+	ErrWorkerLost     = errors.New("job's worker did not send a heartbeat in time and was presumed lost")
+)
+
+// PeriodicSpec describes a cron-like schedule for EnqueuePeriodic.
+type PeriodicSpec struct {
+	// Cron is a standard 5-field cron expression (minute hour dom month
+	// dow), evaluated in TimeZone.
+	Cron string
+
+	// TimeZone is the IANA zone Cron is evaluated in. The empty string
+	// means UTC.
+	TimeZone string
+
+	// ProhibitOverlap skips a tick if the previous child this schedule
+	// produced is still queued or running, instead of enqueueing another
+	// one alongside it, matching Nomad's periodic dispatch semantics.
+	ProhibitOverlap bool
+}
+
+// DefaultCacheTimeout is how long a job with RequiredArtifacts waits for a
+// worker that already has one of them cached before it's offered to any
+// worker that registered for the job type, matching distbuild's local/global
+// queue split: most jobs find a warm worker well before this, so the
+// fallback only kicks in for artifacts nothing currently has cached.
+const DefaultCacheTimeout = 30 * time.Second
+
+// DefaultDepsTimeout bounds how long a job whose dependencies just finished
+// waits for one of the workers that already has those dependencies' outputs
+// cached before it becomes globally schedulable, so a preferred worker that
+// crashed or disconnected can't starve the job forever.
+const DefaultDepsTimeout = 5 * time.Minute
+
+// Config holds the knobs a JobQueue implementation's constructor exposes to
+// tune Dequeue's priority and fair-share policy.
+type Config struct {
+	// MaxConcurrentPerTenant caps how many jobs of a single TenantID may be
+	// running (dequeued but not yet finished) at once. Dequeue skips a
+	// ready job whose tenant is already at this cap in favor of the next
+	// eligible one, so one tenant's backlog can't starve the rest. Zero
+	// means unlimited.
+	MaxConcurrentPerTenant int
+
+	// PriorityBands is the number of distinct priority levels Dequeue
+	// groups jobs into; a job's Priority is clamped into [0, PriorityBands)
+	// before banding. Zero disables banding (all jobs share one band, and
+	// Priority is ignored).
+	PriorityBands int
+}
+
+// JobQueue keeps track of jobs and their dependencies.
+//
+// Goroutines can enqueue new jobs with Enqueue() and dequeue them with
+// Dequeue() or DequeueByID(). Dequeued jobs can be finished with FinishJob().
+// Job queue implementations must be safe for concurrent use.
+//
+// Dequeue is locality-aware: a job enqueued with RequiredArtifacts is first
+// offered only to workers whose reported cache (see WorkerCacheUpdate)
+// intersects that list (the "local" queue), and only falls through to
+// whichever worker calls Dequeue next (the "global" queue) once
+// DefaultCacheTimeout has passed since the job became pending, or
+// DefaultDepsTimeout since its dependencies finished, whichever an
+// implementation uses — this mirrors the shad-go distbuild scheduler's
+// two-queue design, so a missing cache hit costs latency rather than
+// availability. See fsjobqueue's pickByLocality for a reference
+// implementation of this policy.
+//
+// Dequeue also applies fair-share scheduling across the Priority and
+// TenantID an implementation's Config enables: among ready jobs of the
+// requested types, it first narrows to the highest priority band with any
+// ready work, then within that band picks the tenant with the fewest jobs
+// currently running (dequeued but not finished), breaking ties by oldest
+// enqueue time — so a flood of low-priority or single-tenant jobs can't
+// starve everyone else out. A tenant already at Config.MaxConcurrentPerTenant
+// is skipped in favor of the next eligible tenant in the same band.
+type JobQueue interface {
+	// Enqueue a job with the given type and arguments. Returns the id of
+	// the new job. Dependencies are optional and must have already been
+	// enqueued. `args` must be JSON-serializable. requiredArtifacts names
+	// the artifact/source IDs (as reported by WorkerCacheUpdate) this job
+	// would benefit from running next to; it may be nil for jobs with no
+	// useful locality (e.g. one that doesn't read any prior job's output).
+	// priority is the job's band for Dequeue's fair-share policy (higher
+	// runs first); tenantID attributes it to a tenant for the
+	// MaxConcurrentPerTenant cap and fairness tie-breaking. Both are
+	// optional: the zero value of each places a job in the lowest priority
+	// band and an unnamed shared tenant.
+	Enqueue(jobType string, args interface{}, dependencies []uuid.UUID, requiredArtifacts []string, priority int, tenantID string) (uuid.UUID, error)
+
+	// Dequeue returns the next job that is pending (all its dependencies
+	// finished, and its type is in jobTypes) and not already dequeued,
+	// chosen by the priority/fair-share policy described above. It blocks
+	// until such a job is available or ctx is canceled. workerID
+	// identifies the calling worker (see RegisterWorker); within the
+	// chosen priority band and tenant, Dequeue still prefers a job whose
+	// RequiredArtifacts intersect what workerID has reported caching,
+	// falling back to FIFO order once no such job exists or the locality
+	// timeout has passed. workerID may be uuid.Nil, in which case the
+	// caller is only ever offered jobs FIFO, as if it had never cached
+	// anything.
+	Dequeue(ctx context.Context, workerID uuid.UUID, jobTypes []string) (id uuid.UUID, token uuid.UUID, dependencies []uuid.UUID, jobType string, args json.RawMessage, err error)
+
+	// DequeueByID dequeues a specific, already-enqueued job. Returns
+	// ErrNotPending if it's not ready to be dequeued yet (not all its
+	// dependencies have finished, or it has already been dequeued).
+	DequeueByID(ctx context.Context, id uuid.UUID) (token uuid.UUID, dependencies []uuid.UUID, jobType string, args json.RawMessage, err error)
+
+	// FinishJob marks the given (dequeued) job as finished, recording
+	// `result` and notifying anything waiting on it via Notify. Returns
+	// ErrCanceled if the job was canceled — directly, or by a cascade from
+	// one of its dependencies — before this call, instead of silently
+	// recording the result of work that no longer matters to anything.
+	FinishJob(id uuid.UUID, result interface{}) error
+
+	// CancelJob marks a pending or running job as canceled, and cascades:
+	// every job that (transitively) depends on it and has not yet been
+	// dequeued is canceled too. A job that is already running when the
+	// cascade reaches it keeps running, but its eventual FinishJob call
+	// returns ErrCanceled, and WatchCancel fires for whoever is holding
+	// its dequeue token, so a long-running worker can react immediately
+	// instead of only learning about it at FinishJob time. A finished job
+	// cannot be canceled and returns ErrNotRunning. See fsjobqueue's
+	// cancelCascade for a reference implementation of the cascade.
+	CancelJob(id uuid.UUID) error
+
+	// WatchCancel returns a channel that is closed once cancellation is
+	// requested for the job currently holding token — directly via
+	// CancelJob, or by a cascade from one of its dependencies. It is safe
+	// to call on a token whose job is already canceled: the returned
+	// channel is closed immediately.
+	WatchCancel(token uuid.UUID) <-chan struct{}
+
+	// JobStatus returns the current status of a job. parent is the id of
+	// the periodic or parameterized definition this job was materialized
+	// from (see EnqueuePeriodic, Dispatch), or uuid.Nil for a job enqueued
+	// directly with Enqueue. attempts counts how many times RequeueStale
+	// has put this job back to pending after its worker went stale, and
+	// lastError is the error recorded by the most recent such attempt (or
+	// finishing it, if attempts reached RequeueStale's maxAttempts), or ""
+	// if that never happened.
+	JobStatus(id uuid.UUID) (jobType string, result json.RawMessage, queued, started, finished time.Time, canceled bool, deps []uuid.UUID, parent uuid.UUID, attempts int, lastError string, err error)
+
+	// Job returns the job's type, arguments, dependencies, and the same
+	// attempts/lastError RequeueStale history JobStatus reports.
+	Job(id uuid.UUID) (jobType string, args json.RawMessage, deps []uuid.UUID, attempts int, lastError string, err error)
+
+	// IdFromToken returns the id of the job that is currently holding the
+	// given dequeue token, or ErrNotExist.
+	IdFromToken(token uuid.UUID) (uuid.UUID, error)
+
+	// Heartbeats returns the tokens of all dequeued jobs whose last
+	// heartbeat is older than olderThan.
+	Heartbeats(olderThan time.Duration) []uuid.UUID
+
+	// RequeueStale finds every dequeued job whose last heartbeat exceeds
+	// olderThan — i.e. the same set Heartbeats(olderThan) would report —
+	// and, for each: records ErrWorkerLost as its LastError and increments
+	// its Attempts counter, then either returns it to pending (clearing
+	// its token and started time so a fresh Dequeue can pick it up), if
+	// Attempts is now less than maxAttempts, or otherwise finishes it with
+	// a nil result, treating it as permanently failed. Returns the ids of
+	// the jobs it requeued (not the ones it finished as permanently
+	// failed). See fsjobqueue's RequeueStale for a reference
+	// implementation of the attempts/maxAttempts bookkeeping.
+	RequeueStale(olderThan time.Duration, maxAttempts int) ([]uuid.UUID, error)
+
+	// Notify returns a channel that is closed once job transitions into a
+	// pending (ready to dequeue) state, so callers that would otherwise
+	// poll JobStatus/RequestJobById in a loop can block on it instead. The
+	// channel is also closed if job finishes or is canceled before ever
+	// becoming pending (e.g. one of its dependencies failed), so callers
+	// must re-check the job's status after it fires rather than assuming
+	// success. It is safe to call on a job that is already pending: the
+	// returned channel is closed immediately.
+	Notify(job uuid.UUID) <-chan struct{}
+
+	// RegisterWorker records a new worker capable of running the given job
+	// types and returns the WorkerID that identifies it to Dequeue and
+	// WorkerCacheUpdate for the rest of its lifetime. Callers should
+	// persist the returned id across restarts so Dequeue keeps treating
+	// the worker as warm for whatever it last reported cached, instead of
+	// losing locality on every reconnect.
+	RegisterWorker(capabilities []string) (uuid.UUID, error)
+
+	// WorkerCacheUpdate reports a change to workerID's cached artifact/
+	// source set: added lists ids it now has cached, removed lists ones it
+	// has evicted. Dequeue consults the accumulated set to find jobs whose
+	// RequiredArtifacts this worker already has local. Returns
+	// ErrWorkerNotExist if workerID was never returned by RegisterWorker.
+	WorkerCacheUpdate(workerID uuid.UUID, added, removed []string) error
+
+	// EnqueuePeriodic registers a periodic job definition: on each tick of
+	// spec's schedule a child job of jobType is enqueued with
+	// argsTemplate as its args. Returns the id of the definition itself;
+	// it is never runnable and is skipped by Dequeue, but JobStatus and
+	// ChildJobs treat it like the parent Dispatch produces children
+	// under. Ticks missed while the queue isn't running are not backfilled.
+	EnqueuePeriodic(spec PeriodicSpec, jobType string, argsTemplate interface{}) (uuid.UUID, error)
+
+	// EnqueueParameterized registers a parameterized job definition: a
+	// template external callers materialize into concrete child jobs with
+	// Dispatch. metaSchema documents the shape callers' meta overrides
+	// must follow; the queue stores it but does not itself validate
+	// Dispatch calls against it.
+	EnqueueParameterized(jobType string, metaSchema json.RawMessage) (uuid.UUID, error)
+
+	// Dispatch materializes a concrete, runnable child job of parentID (a
+	// definition from EnqueueParameterized), overriding metaSchema's
+	// defaults with metaOverrides and passing payload as the child's
+	// args. Returns ErrNotParent if parentID is not a parameterized
+	// definition.
+	Dispatch(parentID uuid.UUID, metaOverrides interface{}, payload interface{}) (uuid.UUID, error)
+
+	// ChildJobs returns, oldest first, the ids of jobs previously
+	// produced from parentID — whether by a periodic schedule's ticks or
+	// by Dispatch. Returns ErrNotParent if parentID is not a periodic or
+	// parameterized definition.
+	ChildJobs(parentID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Clock is the time source a JobQueue implementation's periodic scheduler
+// consults to decide when a PeriodicSpec's next tick is due.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// RealClock is the Clock every JobQueue implementation uses by default.
+var RealClock Clock = realClock{}
+
+// ClockSetter is implemented by JobQueue backends that support
+// substituting their periodic scheduler's Clock, so tests can drive
+// schedule firing deterministically instead of sleeping past real cron
+// intervals. A backend that doesn't implement it only needs to pass the
+// parts of the jobqueuetest periodic/parameterized suite that don't
+// depend on tick timing.
+type ClockSetter interface {
+	SetClock(clock Clock)
+}
@@ -41,18 +41,39 @@ func TestJobQueue(t *testing.T, makeJobQueue MakeJobQueue) {
 	t.Run("heartbeats", wrap(testHeartbeats))
 	t.Run("timeout", wrap(testDequeueTimeout))
 	t.Run("dequeue-by-id", wrap(testDequeueByID))
+	t.Run("worker-locality", wrap(testWorkerLocality))
+	t.Run("dequeue-wakeup-latency", wrap(testDequeueWakeupLatency))
+	t.Run("periodic-and-parameterized", wrap(testPeriodicAndParameterized))
+	t.Run("cascading-cancellation", wrap(testCascadingCancellation))
+	t.Run("priority-and-fairness", wrap(testPriorityAndFairness))
+	t.Run("requeue-stale", wrap(testRequeueStale))
 }
 
 func pushTestJob(t *testing.T, q jobqueue.JobQueue, jobType string, args interface{}, dependencies []uuid.UUID) uuid.UUID {
 	t.Helper()
-	id, err := q.Enqueue(jobType, args, dependencies)
+	return pushTestJobWithArtifacts(t, q, jobType, args, dependencies, nil)
+}
+
+func pushTestJobWithArtifacts(t *testing.T, q jobqueue.JobQueue, jobType string, args interface{}, dependencies []uuid.UUID, requiredArtifacts []string) uuid.UUID {
+	t.Helper()
+	return pushTestJobFull(t, q, jobType, args, dependencies, requiredArtifacts, 0, "")
+}
+
+func pushTestJobWithPriority(t *testing.T, q jobqueue.JobQueue, jobType string, dependencies []uuid.UUID, priority int, tenantID string) uuid.UUID {
+	t.Helper()
+	return pushTestJobFull(t, q, jobType, nil, dependencies, nil, priority, tenantID)
+}
+
+func pushTestJobFull(t *testing.T, q jobqueue.JobQueue, jobType string, args interface{}, dependencies []uuid.UUID, requiredArtifacts []string, priority int, tenantID string) uuid.UUID {
+	t.Helper()
+	id, err := q.Enqueue(jobType, args, dependencies, requiredArtifacts, priority, tenantID)
 	require.NoError(t, err)
 	require.NotEmpty(t, id)
 	return id
 }
 
 func finishNextTestJob(t *testing.T, q jobqueue.JobQueue, jobType string, result interface{}, deps []uuid.UUID) uuid.UUID {
-	id, tok, d, typ, args, err := q.Dequeue(context.Background(), []string{jobType})
+	id, tok, d, typ, args, err := q.Dequeue(context.Background(), uuid.Nil, []string{jobType})
 	require.NoError(t, err)
 	require.NotEmpty(t, id)
 	require.NotEmpty(t, tok)
@@ -68,18 +89,18 @@ func finishNextTestJob(t *testing.T, q jobqueue.JobQueue, jobType string, result
 
 func testErrors(t *testing.T, q jobqueue.JobQueue) {
 	// not serializable to JSON
-	id, err := q.Enqueue("test", make(chan string), nil)
+	id, err := q.Enqueue("test", make(chan string), nil, nil, 0, "")
 	require.Error(t, err)
 	require.Equal(t, uuid.Nil, id)
 
 	// invalid dependency
-	id, err = q.Enqueue("test", "arg0", []uuid.UUID{uuid.New()})
+	id, err = q.Enqueue("test", "arg0", []uuid.UUID{uuid.New()}, nil, 0, "")
 	require.Error(t, err)
 	require.Equal(t, uuid.Nil, id)
 
 	// token gets removed
 	pushTestJob(t, q, "octopus", nil, nil)
-	id, tok, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"})
+	id, tok, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
 	require.NoError(t, err)
 	require.NotEmpty(t, tok)
 
@@ -110,7 +131,7 @@ func testArgs(t *testing.T, q jobqueue.JobQueue) {
 
 	var parsedArgs argument
 
-	id, tok, deps, typ, args, err := q.Dequeue(context.Background(), []string{"octopus"})
+	id, tok, deps, typ, args, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
 	require.NoError(t, err)
 	require.Equal(t, two, id)
 	require.NotEmpty(t, tok)
@@ -121,13 +142,13 @@ func testArgs(t *testing.T, q jobqueue.JobQueue) {
 	require.Equal(t, twoargs, parsedArgs)
 
 	// Read job params after Dequeue
-	jtype, jargs, jdeps, err := q.Job(id)
+	jtype, jargs, jdeps, _, _, err := q.Job(id)
 	require.NoError(t, err)
 	require.Equal(t, args, jargs)
 	require.Equal(t, deps, jdeps)
 	require.Equal(t, typ, jtype)
 
-	id, tok, deps, typ, args, err = q.Dequeue(context.Background(), []string{"fish"})
+	id, tok, deps, typ, args, err = q.Dequeue(context.Background(), uuid.Nil, []string{"fish"})
 	require.NoError(t, err)
 	require.Equal(t, one, id)
 	require.NotEmpty(t, tok)
@@ -137,13 +158,13 @@ func testArgs(t *testing.T, q jobqueue.JobQueue) {
 	require.NoError(t, err)
 	require.Equal(t, oneargs, parsedArgs)
 
-	jtype, jargs, jdeps, err = q.Job(id)
+	jtype, jargs, jdeps, _, _, err = q.Job(id)
 	require.NoError(t, err)
 	require.Equal(t, args, jargs)
 	require.Equal(t, deps, jdeps)
 	require.Equal(t, typ, jtype)
 
-	_, _, _, err = q.Job(uuid.New())
+	_, _, _, _, _, err = q.Job(uuid.New())
 	require.Error(t, err)
 }
 
@@ -156,7 +177,7 @@ func testJobTypes(t *testing.T, q jobqueue.JobQueue) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	id, tok, deps, typ, args, err := q.Dequeue(ctx, []string{"zebra"})
+	id, tok, deps, typ, args, err := q.Dequeue(ctx, uuid.Nil, []string{"zebra"})
 	require.Equal(t, err, jobqueue.ErrDequeueTimeout)
 	require.Equal(t, uuid.Nil, id)
 	require.Equal(t, uuid.Nil, tok)
@@ -168,12 +189,12 @@ func testJobTypes(t *testing.T, q jobqueue.JobQueue) {
 func testDequeueTimeout(t *testing.T, q jobqueue.JobQueue) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
 	defer cancel()
-	_, _, _, _, _, err := q.Dequeue(ctx, []string{"octopus"})
+	_, _, _, _, _, err := q.Dequeue(ctx, uuid.Nil, []string{"octopus"})
 	require.Equal(t, jobqueue.ErrDequeueTimeout, err)
 
 	ctx2, cancel2 := context.WithCancel(context.Background())
 	cancel2()
-	_, _, _, _, _, err = q.Dequeue(ctx2, []string{"octopus"})
+	_, _, _, _, _, err = q.Dequeue(ctx2, uuid.Nil, []string{"octopus"})
 	require.Equal(t, jobqueue.ErrDequeueTimeout, err)
 }
 
@@ -188,7 +209,7 @@ func testDependencies(t *testing.T, q jobqueue.JobQueue) {
 		require.ElementsMatch(t, []uuid.UUID{one, two}, r)
 
 		j := pushTestJob(t, q, "test", nil, []uuid.UUID{one, two})
-		jobType, _, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		jobType, _, queued, started, finished, canceled, deps, _, _, _, err := q.JobStatus(j)
 		require.NoError(t, err)
 		require.Equal(t, jobType, "test")
 		require.True(t, !queued.IsZero())
@@ -199,7 +220,7 @@ func testDependencies(t *testing.T, q jobqueue.JobQueue) {
 
 		require.Equal(t, j, finishNextTestJob(t, q, "test", testResult{}, []uuid.UUID{one, two}))
 
-		jobType, result, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		jobType, result, queued, started, finished, canceled, deps, _, _, _, err := q.JobStatus(j)
 		require.NoError(t, err)
 		require.Equal(t, jobType, "test")
 		require.True(t, !queued.IsZero())
@@ -217,7 +238,7 @@ func testDependencies(t *testing.T, q jobqueue.JobQueue) {
 		two := pushTestJob(t, q, "test", nil, nil)
 
 		j := pushTestJob(t, q, "test", nil, []uuid.UUID{one, two})
-		jobType, _, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		jobType, _, queued, started, finished, canceled, deps, _, _, _, err := q.JobStatus(j)
 		require.NoError(t, err)
 		require.Equal(t, jobType, "test")
 		require.True(t, !queued.IsZero())
@@ -233,7 +254,7 @@ func testDependencies(t *testing.T, q jobqueue.JobQueue) {
 
 		require.Equal(t, j, finishNextTestJob(t, q, "test", testResult{}, []uuid.UUID{one, two}))
 
-		jobType, result, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		jobType, result, queued, started, finished, canceled, deps, _, _, _, err := q.JobStatus(j)
 		require.NoError(t, err)
 		require.Equal(t, jobType, "test")
 		require.True(t, !queued.IsZero())
@@ -255,7 +276,7 @@ func testMultipleWorkers(t *testing.T, q jobqueue.JobQueue) {
 		defer close(done)
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		id, tok, deps, typ, args, err := q.Dequeue(ctx, []string{"octopus"})
+		id, tok, deps, typ, args, err := q.Dequeue(ctx, uuid.Nil, []string{"octopus"})
 		require.NoError(t, err)
 		require.NotEmpty(t, id)
 		require.NotEmpty(t, tok)
@@ -270,7 +291,7 @@ func testMultipleWorkers(t *testing.T, q jobqueue.JobQueue) {
 
 	// This call to Dequeue() should not block on the one in the goroutine.
 	id := pushTestJob(t, q, "clownfish", nil, nil)
-	r, tok, deps, typ, args, err := q.Dequeue(context.Background(), []string{"clownfish"})
+	r, tok, deps, typ, args, err := q.Dequeue(context.Background(), uuid.Nil, []string{"clownfish"})
 	require.NoError(t, err)
 	require.Equal(t, id, r)
 	require.NotEmpty(t, tok)
@@ -293,7 +314,7 @@ func testMultipleWorkersSingleJobType(t *testing.T, q jobqueue.JobQueue) {
 			defer wg.Add(-1)
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
-			id, tok, deps, typ, args, err := q.Dequeue(ctx, []string{"clownfish"})
+			id, tok, deps, typ, args, err := q.Dequeue(ctx, uuid.Nil, []string{"clownfish"})
 			require.NoError(t, err)
 			require.NotEmpty(t, id)
 			require.NotEmpty(t, tok)
@@ -329,18 +350,18 @@ func testCancel(t *testing.T, q jobqueue.JobQueue) {
 	require.NotEmpty(t, id)
 	err = q.CancelJob(id)
 	require.NoError(t, err)
-	jobType, result, _, _, _, canceled, _, err := q.JobStatus(id)
+	jobType, result, _, _, _, canceled, _, _, _, _, err := q.JobStatus(id)
 	require.NoError(t, err)
 	require.Equal(t, jobType, "clownfish")
 	require.True(t, canceled)
 	require.Nil(t, result)
 	err = q.FinishJob(id, &testResult{})
-	require.Error(t, err)
+	require.Equal(t, jobqueue.ErrCanceled, err)
 
 	// Cancel a running job, which should not dequeue the canceled job from above
 	id = pushTestJob(t, q, "clownfish", nil, nil)
 	require.NotEmpty(t, id)
-	r, tok, deps, typ, args, err := q.Dequeue(context.Background(), []string{"clownfish"})
+	r, tok, deps, typ, args, err := q.Dequeue(context.Background(), uuid.Nil, []string{"clownfish"})
 	require.NoError(t, err)
 	require.Equal(t, id, r)
 	require.NotEmpty(t, tok)
@@ -349,18 +370,18 @@ func testCancel(t *testing.T, q jobqueue.JobQueue) {
 	require.Equal(t, json.RawMessage("null"), args)
 	err = q.CancelJob(id)
 	require.NoError(t, err)
-	jobType, result, _, _, _, canceled, _, err = q.JobStatus(id)
+	jobType, result, _, _, _, canceled, _, _, _, _, err = q.JobStatus(id)
 	require.NoError(t, err)
 	require.Equal(t, jobType, "clownfish")
 	require.True(t, canceled)
 	require.Nil(t, result)
 	err = q.FinishJob(id, &testResult{})
-	require.Error(t, err)
+	require.Equal(t, jobqueue.ErrCanceled, err)
 
 	// Cancel a finished job, which is a no-op
 	id = pushTestJob(t, q, "clownfish", nil, nil)
 	require.NotEmpty(t, id)
-	r, tok, deps, typ, args, err = q.Dequeue(context.Background(), []string{"clownfish"})
+	r, tok, deps, typ, args, err = q.Dequeue(context.Background(), uuid.Nil, []string{"clownfish"})
 	require.NoError(t, err)
 	require.Equal(t, id, r)
 	require.NotEmpty(t, tok)
@@ -372,7 +393,7 @@ func testCancel(t *testing.T, q jobqueue.JobQueue) {
 	err = q.CancelJob(id)
 	require.Error(t, err)
 	require.Equal(t, jobqueue.ErrNotRunning, err)
-	jobType, result, _, _, _, canceled, _, err = q.JobStatus(id)
+	jobType, result, _, _, _, canceled, _, _, _, _, err = q.JobStatus(id)
 	require.NoError(t, err)
 	require.Equal(t, jobType, "clownfish")
 	require.False(t, canceled)
@@ -385,7 +406,7 @@ func testHeartbeats(t *testing.T, q jobqueue.JobQueue) {
 	// No heartbeats for queued job
 	require.Empty(t, q.Heartbeats(time.Second*0))
 
-	r, tok, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"})
+	r, tok, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
 	require.NoError(t, err)
 	require.Equal(t, id, r)
 	require.NotEmpty(t, tok)
@@ -447,7 +468,7 @@ func testDequeueByID(t *testing.T, q jobqueue.JobQueue) {
 	t.Run("cannot dequeue a non-pending job", func(t *testing.T) {
 		one := pushTestJob(t, q, "octopus", nil, nil)
 
-		_, _, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"})
+		_, _, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
 		require.NoError(t, err)
 
 		_, _, _, _, err = q.DequeueByID(context.Background(), one)
@@ -460,3 +481,448 @@ func testDequeueByID(t *testing.T, q jobqueue.JobQueue) {
 		require.Equal(t, jobqueue.ErrNotPending, err)
 	})
 }
+
+// testWorkerLocality exercises Dequeue's preference for a job whose
+// RequiredArtifacts intersect the calling worker's reported cache. The
+// timeout-based fallback to a cold worker (DefaultCacheTimeout /
+// DefaultDepsTimeout) isn't exercised here: it isn't observable without
+// either sleeping past those timeouts in real time or a backend exposing
+// them as configurable, so it's left to each backend's own test suite.
+func testWorkerLocality(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("prefers-worker-with-matching-cache", func(t *testing.T) {
+		warm, err := q.RegisterWorker([]string{"octopus"})
+		require.NoError(t, err)
+		err = q.WorkerCacheUpdate(warm, []string{"layer-a"}, nil)
+		require.NoError(t, err)
+
+		// Enqueued first, but nothing it needs is cached anywhere.
+		cold := pushTestJob(t, q, "octopus", nil, nil)
+		// Enqueued second, but the warm worker already has its input.
+		local := pushTestJobWithArtifacts(t, q, "octopus", nil, nil, []string{"layer-a"})
+
+		id, _, _, _, _, err := q.Dequeue(context.Background(), warm, []string{"octopus"})
+		require.NoError(t, err)
+		require.Equal(t, local, id, "should prefer the job the calling worker already has cached input for")
+		require.NoError(t, q.FinishJob(id, nil))
+
+		id, _, _, _, _, err = q.Dequeue(context.Background(), warm, []string{"octopus"})
+		require.NoError(t, err)
+		require.Equal(t, cold, id)
+		require.NoError(t, q.FinishJob(id, nil))
+	})
+
+	t.Run("does-not-starve-when-no-worker-has-the-cache", func(t *testing.T) {
+		stranger, err := q.RegisterWorker([]string{"octopus"})
+		require.NoError(t, err)
+
+		// No worker has ever reported "layer-z" cached, so this must still
+		// be handed out rather than waiting for a worker that never comes.
+		id := pushTestJobWithArtifacts(t, q, "octopus", nil, nil, []string{"layer-z"})
+
+		got, _, _, _, _, err := q.Dequeue(context.Background(), stranger, []string{"octopus"})
+		require.NoError(t, err)
+		require.Equal(t, id, got)
+		require.NoError(t, q.FinishJob(got, nil))
+	})
+
+	t.Run("unregistered worker", func(t *testing.T) {
+		err := q.WorkerCacheUpdate(uuid.New(), []string{"layer-a"}, nil)
+		require.Equal(t, jobqueue.ErrWorkerNotExist, err)
+	})
+}
+
+// testDequeueWakeupLatency asserts that Dequeue wakes a blocked caller
+// promptly once a matching job is enqueued, instead of only eventually
+// noticing it on the next poll. Implementations backed by a fixed poll
+// interval (rather than a push notification on Enqueue) are expected to
+// fail this within the deadline below; it exists to hold backends that
+// claim push-based wakeup to that promise under concurrency, not just in
+// the single-waiter case testMultipleWorkersSingleJobType already covers.
+func testDequeueWakeupLatency(t *testing.T, q jobqueue.JobQueue) {
+	const waiters = 20
+	const wakeupDeadline = 100 * time.Millisecond
+
+	woken := make(chan time.Time, waiters)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			defer wg.Done()
+			id, _, _, typ, _, err := q.Dequeue(ctx, uuid.Nil, []string{"clownfish"})
+			if err != nil {
+				// The deadline fired before this goroutine's Dequeue saw
+				// the job; the assertions below on the winner still hold.
+				return
+			}
+			require.Equal(t, "clownfish", typ)
+			require.NotEmpty(t, id)
+			woken <- time.Now()
+		}()
+	}
+
+	// Give every waiter a chance to actually block in Dequeue before the
+	// job exists, so we're timing wakeup latency, not goroutine startup.
+	time.Sleep(10 * time.Millisecond)
+
+	enqueued := time.Now()
+	pushTestJob(t, q, "clownfish", nil, nil)
+
+	var wokeAt time.Time
+	select {
+	case wokeAt = <-woken:
+	case <-time.After(wakeupDeadline + time.Second):
+		t.Fatal("no waiter was woken for the enqueued job")
+	}
+	require.WithinDuration(t, enqueued, wokeAt, wakeupDeadline, "Dequeue should wake a blocked waiter via push notification, not a slow poll loop")
+
+	cancel()
+	wg.Wait()
+}
+
+// fakeClock lets testPeriodicAndParameterized advance a periodic
+// scheduler's notion of "now" deterministically, instead of sleeping past
+// real cron intervals.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(start time.Time) *fakeClock {
+	return &fakeClock{now: start}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// testPeriodicAndParameterized covers EnqueuePeriodic's schedule firing and
+// ProhibitOverlap, and EnqueueParameterized's Dispatch fan-out. The
+// schedule-firing subtests only run against backends that implement
+// jobqueue.ClockSetter, since without one there's no way to make a tick
+// happen without sleeping past its real-time Cron interval.
+func testPeriodicAndParameterized(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("schedule firing", func(t *testing.T) {
+		cs, ok := q.(jobqueue.ClockSetter)
+		if !ok {
+			t.Skip("backend does not implement jobqueue.ClockSetter")
+		}
+		clock := newFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		cs.SetClock(clock)
+		defer cs.SetClock(jobqueue.RealClock)
+
+		parent, err := q.EnqueuePeriodic(jobqueue.PeriodicSpec{Cron: "* * * * *"}, "octopus", nil)
+		require.NoError(t, err)
+
+		children, err := q.ChildJobs(parent)
+		require.NoError(t, err)
+		require.Empty(t, children, "no tick has fired yet")
+
+		clock.Advance(time.Minute)
+		require.Eventually(t, func() bool {
+			children, err := q.ChildJobs(parent)
+			return err == nil && len(children) == 1
+		}, time.Second, 10*time.Millisecond, "a child job should be materialized once the schedule ticks")
+
+		children, err = q.ChildJobs(parent)
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+
+		jobType, _, _, _, _, _, _, gotParent, _, _, err := q.JobStatus(children[0])
+		require.NoError(t, err)
+		require.Equal(t, "octopus", jobType)
+		require.Equal(t, parent, gotParent)
+
+		// Cancel the child so it doesn't linger as a queued "octopus" job
+		// that a later subtest's FIFO tie-break could pick up instead of
+		// its own child.
+		require.NoError(t, q.CancelJob(children[0]))
+	})
+
+	t.Run("prohibit overlap skips a tick while the previous child is still pending", func(t *testing.T) {
+		cs, ok := q.(jobqueue.ClockSetter)
+		if !ok {
+			t.Skip("backend does not implement jobqueue.ClockSetter")
+		}
+		clock := newFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		cs.SetClock(clock)
+		defer cs.SetClock(jobqueue.RealClock)
+
+		parent, err := q.EnqueuePeriodic(jobqueue.PeriodicSpec{Cron: "* * * * *", ProhibitOverlap: true}, "octopus", nil)
+		require.NoError(t, err)
+
+		clock.Advance(time.Minute)
+		require.Eventually(t, func() bool {
+			children, err := q.ChildJobs(parent)
+			return err == nil && len(children) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		// The first child is still queued, so the next tick must be skipped.
+		clock.Advance(time.Minute)
+		time.Sleep(50 * time.Millisecond)
+		children, err := q.ChildJobs(parent)
+		require.NoError(t, err)
+		require.Len(t, children, 1, "ProhibitOverlap should skip a tick while the previous child is still queued/running")
+
+		// Once the pending child finishes, the next tick may fire again.
+		require.Equal(t, children[0], finishNextTestJob(t, q, "octopus", testResult{}, nil))
+		clock.Advance(time.Minute)
+		require.Eventually(t, func() bool {
+			children, err := q.ChildJobs(parent)
+			return err == nil && len(children) == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("dispatch fan-out with independent cancellation", func(t *testing.T) {
+		parent, err := q.EnqueueParameterized("octopus", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+
+		one, err := q.Dispatch(parent, map[string]string{"region": "a"}, nil)
+		require.NoError(t, err)
+		two, err := q.Dispatch(parent, map[string]string{"region": "b"}, nil)
+		require.NoError(t, err)
+
+		children, err := q.ChildJobs(parent)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uuid.UUID{one, two}, children)
+
+		require.NoError(t, q.CancelJob(one))
+		_, _, _, _, _, canceled, _, _, _, _, err := q.JobStatus(one)
+		require.NoError(t, err)
+		require.True(t, canceled)
+
+		_, _, _, _, _, canceled, _, _, _, _, err = q.JobStatus(two)
+		require.NoError(t, err)
+		require.False(t, canceled, "canceling one dispatched child must not affect its siblings")
+	})
+
+	t.Run("not a parent", func(t *testing.T) {
+		_, err := q.ChildJobs(uuid.New())
+		require.Equal(t, jobqueue.ErrNotParent, err)
+
+		_, err = q.Dispatch(uuid.New(), nil, nil)
+		require.Equal(t, jobqueue.ErrNotParent, err)
+	})
+}
+
+// testCascadingCancellation covers CancelJob's cascade onto dependents and
+// the WatchCancel signal it raises for a dependent that's already running.
+func testCascadingCancellation(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("diamond dependency graph is canceled exactly once", func(t *testing.T) {
+		root := pushTestJob(t, q, "test", nil, nil)
+		left := pushTestJob(t, q, "test", nil, []uuid.UUID{root})
+		right := pushTestJob(t, q, "test", nil, []uuid.UUID{root})
+		bottom := pushTestJob(t, q, "test", nil, []uuid.UUID{left, right})
+
+		require.NoError(t, q.CancelJob(root))
+
+		for _, id := range []uuid.UUID{root, left, right, bottom} {
+			_, _, _, _, _, canceled, _, _, _, _, err := q.JobStatus(id)
+			require.NoError(t, err)
+			require.True(t, canceled, "job %s should have been canceled by the cascade", id)
+		}
+
+		// Canceling an already-canceled dependent again is a no-op, not a
+		// second cascade that would somehow double-cancel bottom.
+		require.NoError(t, q.CancelJob(left))
+		_, _, _, _, _, canceled, _, _, _, _, err := q.JobStatus(bottom)
+		require.NoError(t, err)
+		require.True(t, canceled)
+	})
+
+	t.Run("watch cancel fires for a running dependent", func(t *testing.T) {
+		root := pushTestJob(t, q, "test", nil, nil)
+		dependent := pushTestJob(t, q, "test", nil, []uuid.UUID{root})
+
+		require.Equal(t, root, finishNextTestJob(t, q, "test", testResult{}, nil))
+
+		_, tok, _, typ, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"test"})
+		require.NoError(t, err)
+		require.Equal(t, "test", typ)
+
+		watch := q.WatchCancel(tok)
+		select {
+		case <-watch:
+			t.Fatal("WatchCancel fired before the dependent's root was ever canceled")
+		default:
+		}
+
+		require.NoError(t, q.CancelJob(dependent))
+
+		select {
+		case <-watch:
+		case <-time.After(time.Second):
+			t.Fatal("WatchCancel did not fire within a second of CancelJob")
+		}
+	})
+
+	t.Run("finishing a cascade-canceled dependent returns ErrCanceled", func(t *testing.T) {
+		root := pushTestJob(t, q, "test", nil, nil)
+		dependent := pushTestJob(t, q, "test", nil, []uuid.UUID{root})
+
+		require.NoError(t, q.CancelJob(root))
+
+		_, _, _, _, _, canceled, _, _, _, _, err := q.JobStatus(dependent)
+		require.NoError(t, err)
+		require.True(t, canceled, "canceling root should cascade to its not-yet-dequeued dependent")
+
+		err = q.FinishJob(dependent, testResult{})
+		require.Equal(t, jobqueue.ErrCanceled, err)
+	})
+}
+
+// testPriorityAndFairness covers Dequeue's priority/fair-share policy. It
+// assumes makeJobQueue configures the instance under test with at least two
+// PriorityBands and a MaxConcurrentPerTenant of 1, the way a backend's own
+// test harness would for exercising this policy at all — jobqueuetest has
+// no way to tune those constructor-level knobs itself.
+func testPriorityAndFairness(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("high priority jumps ahead of a large backlog", func(t *testing.T) {
+		backlog := make([]uuid.UUID, 50)
+		for i := range backlog {
+			backlog[i] = pushTestJobWithPriority(t, q, "test", nil, 0, "")
+		}
+		urgent := pushTestJobWithPriority(t, q, "test", nil, 1, "")
+
+		id, _, _, typ, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"test"})
+		require.NoError(t, err)
+		require.Equal(t, "test", typ)
+		require.Equal(t, urgent, id, "the higher priority band should be drained before the backlog")
+
+		// Clean up so this subtest's backlog doesn't linger as the oldest
+		// ready "test" jobs and skew the FIFO tie-break the later subtests
+		// in this function depend on.
+		require.NoError(t, q.FinishJob(urgent, testResult{}))
+		for _, id := range backlog {
+			require.NoError(t, q.CancelJob(id))
+		}
+	})
+
+	t.Run("a tenant at its concurrency cap is skipped", func(t *testing.T) {
+		tenantAFirst := pushTestJobWithPriority(t, q, "test", nil, 0, "tenant-a")
+		tenantASecond := pushTestJobWithPriority(t, q, "test", nil, 0, "tenant-a")
+
+		id, _, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"test"})
+		require.NoError(t, err)
+		require.Equal(t, tenantAFirst, id, "tenant-a's oldest job should run first since no tenant is yet at its cap")
+
+		tenantB := pushTestJobWithPriority(t, q, "test", nil, 0, "tenant-b")
+
+		// tenant-a is now at MaxConcurrentPerTenant with tenantAFirst still
+		// running, so tenant-b should be preferred over tenant-a's second
+		// job even though it was enqueued later.
+		id, _, _, _, _, err = q.Dequeue(context.Background(), uuid.Nil, []string{"test"})
+		require.NoError(t, err)
+		require.Equal(t, tenantB, id)
+
+		require.NoError(t, q.FinishJob(tenantAFirst, testResult{}))
+
+		id, _, _, _, _, err = q.Dequeue(context.Background(), uuid.Nil, []string{"test"})
+		require.NoError(t, err)
+		require.Equal(t, tenantASecond, id, "tenant-a's second job becomes eligible once its first finishes")
+	})
+
+	t.Run("priority is respected across dependency completion", func(t *testing.T) {
+		root := pushTestJobWithPriority(t, q, "test", nil, 0, "")
+		urgentDependent := pushTestJobWithPriority(t, q, "test", []uuid.UUID{root}, 1, "")
+		backlog := pushTestJobWithPriority(t, q, "test", nil, 0, "")
+
+		require.Equal(t, root, finishNextTestJob(t, q, "test", testResult{}, nil))
+
+		id, _, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"test"})
+		require.NoError(t, err)
+		require.Equal(t, urgentDependent, id, "the freshly-unblocked high-priority job should win over the low-priority backlog")
+
+		require.Equal(t, backlog, finishNextTestJob(t, q, "test", testResult{}, nil))
+	})
+}
+
+// testRequeueStale covers RequeueStale's cleanup of jobs whose worker
+// stopped heartbeating: putting them back to pending while attempts remain,
+// and finishing them as permanently failed once maxAttempts is reached.
+func testRequeueStale(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("requeued job is picked up by another worker", func(t *testing.T) {
+		id := pushTestJob(t, q, "octopus", nil, nil)
+		_, tok, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
+		require.NoError(t, err)
+		require.Contains(t, q.Heartbeats(time.Second*0), tok)
+
+		requeued, err := q.RequeueStale(time.Second*0, 3)
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{id}, requeued)
+
+		// The stale token is gone, and the job is pending again for a
+		// fresh Dequeue to pick up.
+		_, err = q.IdFromToken(tok)
+		require.Equal(t, jobqueue.ErrNotExist, err)
+
+		_, _, _, _, _, _, _, _, attempts, lastError, err := q.JobStatus(id)
+		require.NoError(t, err)
+		require.Equal(t, 1, attempts)
+		require.Equal(t, jobqueue.ErrWorkerLost.Error(), lastError)
+
+		require.Equal(t, id, finishNextTestJob(t, q, "octopus", testResult{}, nil))
+	})
+
+	t.Run("finished as permanently failed once maxAttempts is reached", func(t *testing.T) {
+		id := pushTestJob(t, q, "octopus", nil, nil)
+
+		// First stale heartbeat: attempts goes from 0 to 1, below
+		// maxAttempts of 2, so the job is requeued.
+		_, _, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
+		require.NoError(t, err)
+		requeued, err := q.RequeueStale(time.Second*0, 2)
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{id}, requeued)
+
+		// Second stale heartbeat: attempts reaches 2, the maxAttempts
+		// limit, so the job is finished instead of requeued again.
+		_, _, _, _, _, err = q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
+		require.NoError(t, err)
+		requeued, err = q.RequeueStale(time.Second*0, 2)
+		require.NoError(t, err)
+		require.Empty(t, requeued, "a job that just reached maxAttempts should be finished, not requeued again")
+
+		_, result, _, _, finished, canceled, _, _, attempts, lastError, err := q.JobStatus(id)
+		require.NoError(t, err)
+		require.False(t, canceled)
+		require.False(t, finished.IsZero())
+		require.Nil(t, result)
+		require.Equal(t, 2, attempts)
+		require.Equal(t, jobqueue.ErrWorkerLost.Error(), lastError)
+
+		// Permanently failed, so a fresh Dequeue must never offer it again.
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, _, _, _, _, err = q.Dequeue(ctx, uuid.Nil, []string{"octopus"})
+		require.Equal(t, jobqueue.ErrDequeueTimeout, err)
+	})
+
+	t.Run("no-op on a job whose worker heartbeated recently", func(t *testing.T) {
+		id := pushTestJob(t, q, "octopus", nil, nil)
+		_, tok, _, _, _, err := q.Dequeue(context.Background(), uuid.Nil, []string{"octopus"})
+		require.NoError(t, err)
+
+		requeued, err := q.RequeueStale(time.Hour, 3)
+		require.NoError(t, err)
+		require.Empty(t, requeued)
+
+		require.Contains(t, q.Heartbeats(time.Second*0), tok)
+		_, _, _, _, _, _, _, _, attempts, lastError, err := q.JobStatus(id)
+		require.NoError(t, err)
+		require.Equal(t, 0, attempts)
+		require.Equal(t, "", lastError)
+
+		require.NoError(t, q.FinishJob(id, testResult{}))
+	})
+}
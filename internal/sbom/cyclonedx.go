@@ -0,0 +1,78 @@
+package sbom
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// cyclonedxSpecVersion is the CycloneDX spec version the document below
+// declares.
+const cyclonedxSpecVersion = "1.5"
+
+type cyclonedxDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cyclonedxMetadata    `json:"metadata"`
+	Components   []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxMetadata struct {
+	Component cyclonedxComponent `json:"component"`
+}
+
+type cyclonedxComponent struct {
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	PURL    string          `json:"purl,omitempty"`
+	Hashes  []cyclonedxHash `json:"hashes,omitempty"`
+}
+
+type cyclonedxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+func newCycloneDXDocument(imageName string, packages []rpmmd.PackageSpec, ostreeCommit string) *cyclonedxDocument {
+	doc := &cyclonedxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cyclonedxSpecVersion,
+		SerialNumber: fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+		Version:      1,
+		Metadata: cyclonedxMetadata{
+			Component: cyclonedxComponent{
+				Type: "container",
+				Name: imageName,
+			},
+		},
+	}
+
+	for _, p := range packages {
+		component := cyclonedxComponent{
+			Type:    "library",
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    fmt.Sprintf("pkg:rpm/%s@%s-%s?arch=%s", p.Name, p.Version, p.Release, p.Arch),
+		}
+		if p.Checksum != "" {
+			component.Hashes = []cyclonedxHash{{Alg: checksumAlgorithm(p.Checksum), Content: checksumValue(p.Checksum)}}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+
+	if ostreeCommit != "" {
+		doc.Components = append(doc.Components, cyclonedxComponent{
+			Type:    "file",
+			Name:    "ostree-commit",
+			Version: ostreeCommit,
+			Hashes:  []cyclonedxHash{{Alg: "SHA-256", Content: ostreeCommit}},
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,82 @@
+// Package sbom generates a minimal Software Bill of Materials for an image
+// from the package set osbuild-composer already depsolved for it, in
+// either of the two formats image-builder users ask for: SPDX or
+// CycloneDX.
+//
+// It does not resolve packages itself or talk to a syft binary; it simply
+// re-shapes the rpmmd.PackageSpec list a DepsolveJobResult already carries
+// into the subset of either schema that's useful for an RPM-based image
+// (name, version, license, checksum), so it stays a pure function of data
+// osbuild-composer already has on hand.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// Format selects which SBOM schema Generate produces.
+type Format string
+
+const (
+	FormatSPDX      Format = "spdx"
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// Generate builds an SBOM document for imageName from the given depsolved
+// package set, in the requested format. ostreeCommit is the image's
+// OSTree commit hash, if it has one, and is recorded as an extra entry
+// alongside the RPM packages so the SBOM still accounts for the one part
+// of an ostree-based image's content that isn't an RPM.
+func Generate(format Format, imageName string, packages []rpmmd.PackageSpec, ostreeCommit string) ([]byte, string, error) {
+	switch format {
+	case FormatSPDX:
+		doc := newSPDXDocument(imageName, packages, ostreeCommit)
+		return marshalJSON(doc)
+	case FormatCycloneDX:
+		doc := newCycloneDXDocument(imageName, packages, ostreeCommit)
+		return marshalJSON(doc)
+	default:
+		return nil, "", fmt.Errorf("sbom: unknown format %q", format)
+	}
+}
+
+func marshalJSON(doc interface{}) ([]byte, string, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("sbom: marshaling document: %w", err)
+	}
+	return data, "application/json", nil
+}
+
+// packageEVR is a package's "version-release" the way SPDX's versionInfo
+// field expects it, e.g. "5.1.8-6.el9".
+func packageEVR(p rpmmd.PackageSpec) string {
+	evr := p.Version
+	if p.Release != "" {
+		evr += "-" + p.Release
+	}
+	return evr
+}
+
+// checksumAlgorithm and checksumValue split a PackageSpec's "algo:hex"
+// checksum (e.g. the repo metadata's "sha256:abc...") the way both SBOM
+// formats expect it: algorithm and value as separate fields.
+func checksumAlgorithm(checksum string) string {
+	parts := strings.SplitN(checksum, ":", 2)
+	if len(parts) != 2 {
+		return "SHA256"
+	}
+	return strings.ToUpper(parts[0])
+}
+
+func checksumValue(checksum string) string {
+	parts := strings.SplitN(checksum, ":", 2)
+	if len(parts) != 2 {
+		return checksum
+	}
+	return parts[1]
+}
@@ -0,0 +1,70 @@
+package sbom
+
+import (
+	"fmt"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// spdxVersion is the SPDX spec version the document below declares.
+const spdxVersion = "SPDX-2.3"
+
+type spdxDocument struct {
+	SPDXVersion       string        `json:"spdxVersion"`
+	DataLicense       string        `json:"dataLicense"`
+	SPDXID            string        `json:"SPDXID"`
+	Name              string        `json:"name"`
+	DocumentNamespace string        `json:"documentNamespace"`
+	Packages          []spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	SPDXID           string         `json:"SPDXID"`
+	Name             string         `json:"name"`
+	VersionInfo      string         `json:"versionInfo"`
+	DownloadLocation string         `json:"downloadLocation"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	Checksums        []spdxChecksum `json:"checksums,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+func newSPDXDocument(imageName string, packages []rpmmd.PackageSpec, ostreeCommit string) *spdxDocument {
+	doc := &spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              imageName,
+		DocumentNamespace: fmt.Sprintf("https://osbuild.org/spdx/%s", imageName),
+	}
+
+	for i, p := range packages {
+		pkg := spdxPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i),
+			Name:             p.Name,
+			VersionInfo:      packageEVR(p),
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+		}
+		if p.Checksum != "" {
+			pkg.Checksums = []spdxChecksum{{Algorithm: checksumAlgorithm(p.Checksum), ChecksumValue: checksumValue(p.Checksum)}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+
+	if ostreeCommit != "" {
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           "SPDXRef-Package-ostree-commit",
+			Name:             "ostree-commit",
+			VersionInfo:      ostreeCommit,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			Checksums:        []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: ostreeCommit}},
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,129 @@
+// Package cosign signs an image digest in cosign's signature format: a
+// "simple signing" payload (the digest plus a fixed type field, following
+// the same JSON shape `cosign sign` embeds) wrapped in a DSSE envelope. The
+// same DSSE envelope is also how the attestation package signs in-toto
+// statements, via the more general SignDSSE.
+//
+// It speaks the wire format only; the actual key material handling (a
+// local private key, a KMS reference, or the keyless Fulcio/Rekor flow) is
+// behind the Signer interface so the worker can choose one without this
+// package needing to know about either.
+package cosign
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// simpleSigningMediaType is the payload type cosign uses for its default
+// "simple signing" format, as embedded in the DSSE envelope's payloadType.
+const simpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// InTotoMediaType is the payloadType an in-toto attestation (e.g. a SLSA
+// provenance statement) is wrapped in, per the in-toto attestation spec.
+const InTotoMediaType = "application/vnd.in-toto+json"
+
+// Signer signs an arbitrary payload and identifies the key used, so the
+// envelope can be verified later without the caller needing to track
+// key metadata separately. payloadType is the DSSE payloadType the caller
+// intends to wrap payload in (simple-signing, an in-toto attestation, ...);
+// it's part of what's actually signed, via the DSSE pre-authentication
+// encoding, so a signature can't be replayed across a different envelope.
+type Signer interface {
+	// Sign returns a signature over payload, and the PEM-encoded public
+	// key (or certificate, in keyless mode) that verifies it.
+	Sign(payloadType string, payload []byte) (signature []byte, publicKey []byte, err error)
+}
+
+// simpleSigningPayload is the "simple signing" JSON document cosign signs:
+// the image digest plus a fixed type field.
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"docker-manifest-digest"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// Envelope is a DSSE envelope (https://github.com/secure-systems-lab/dsse),
+// matching the shape cosign writes to registries and to Rekor whether it
+// wraps a simple-signing payload or (via SignDSSE) an in-toto attestation.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []signature `json:"signatures"`
+}
+
+type signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// Sign builds the simple-signing payload for imageRef@digest, signs it with
+// signer, and returns the resulting DSSE envelope.
+//
+// digest is the algo:hex image digest (e.g. "sha256:abc..."); imageRef is
+// the repository the digest belongs to, without a tag.
+func Sign(signer Signer, imageRef, digest string) (*Envelope, error) {
+	var payload simpleSigningPayload
+	payload.Critical.Identity.DockerReference = imageRef
+	payload.Critical.Image.DockerManifestDigest = digest
+	payload.Critical.Type = "cosign container image signature"
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("cosign: marshaling simple-signing payload: %w", err)
+	}
+
+	return SignDSSE(signer, simpleSigningMediaType, payloadBytes)
+}
+
+// SignDSSE wraps an arbitrary payload (already marshaled to its wire
+// format) in a DSSE envelope of the given payloadType, signed by signer.
+// Sign above is the simple-signing case of this; attestation.Sign uses it
+// directly for in-toto statements, so both share one envelope format.
+func SignDSSE(signer Signer, payloadType string, payload []byte) (*Envelope, error) {
+	sig, _, err := signer.Sign(payloadType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("cosign: signing payload: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []signature{
+			{Sig: base64.StdEncoding.EncodeToString(sig)},
+		},
+	}, nil
+}
+
+// pae is the DSSE "pre-authentication encoding" that's actually signed,
+// rather than the raw payload, so a signature can't be replayed across a
+// different payload type by mistake.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// KeySigner signs with a crypto.Signer backed by a local private key, e.g.
+// one loaded from the path or KMS URI given in SigningOptions.KeyRef.
+type KeySigner struct {
+	Key       crypto.Signer
+	PublicKey []byte
+}
+
+func (s *KeySigner) Sign(payloadType string, payload []byte) ([]byte, []byte, error) {
+	sum := sha256.Sum256(pae(payloadType, payload))
+	sig, err := s.Key.Sign(rand.Reader, sum[:], crypto.SHA256)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cosign: signing with local key: %w", err)
+	}
+	return sig, s.PublicKey, nil
+}
@@ -0,0 +1,48 @@
+package target
+
+// AzureImageTargetOptions describe where an image built by osbuild should
+// be uploaded and registered as an Azure managed image once the compose
+// finishes.
+type AzureImageTargetOptions struct {
+	Filename       string `json:"filename"`
+	TenantID       string `json:"tenant_id"`
+	Location       string `json:"location"`
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+
+	// StagingContainer is a container in the same storage account used to
+	// stage the post-upload page-blob conversion, since a blob can't be
+	// rewritten as a different blob type in place. Defaults to
+	// "osbuild-page-blob-staging" if empty.
+	StagingContainer string `json:"staging_container,omitempty"`
+}
+
+func (*AzureImageTargetOptions) isTargetOptions() {}
+
+// AzureImageTargetResultOptions is reported back once the uploader has
+// registered the image, plus whatever the follow-on AzurePathFixJob found
+// and did to the uploaded blob.
+type AzureImageTargetResultOptions struct {
+	ImageName string `json:"image_name"`
+
+	// PathFixApplied is true once AzurePathFixJob has run against the
+	// uploaded blob, whether or not it actually needed converting.
+	PathFixApplied bool `json:"path_fix_applied,omitempty"`
+	// PreFixBlobURL and PostFixBlobURL are the blob URL before and after
+	// the fixup; they differ only when the blob had to be restaged under
+	// a new page-blob-backed URL.
+	PreFixBlobURL  string `json:"pre_fix_blob_url,omitempty"`
+	PostFixBlobURL string `json:"post_fix_blob_url,omitempty"`
+	// PathFixError records why the fixup failed, so operators can see
+	// whether it ran and what it did without digging through worker logs.
+	PathFixError string `json:"path_fix_error,omitempty"`
+}
+
+func (*AzureImageTargetResultOptions) isTargetResultOptions() {}
+
+func NewAzureImageTarget(options *AzureImageTargetOptions) *Target {
+	return &Target{
+		Name:    "org.osbuild.azure.image",
+		Options: options,
+	}
+}
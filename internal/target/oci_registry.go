@@ -0,0 +1,52 @@
+package target
+
+// OCIRegistryTargetOptions describe where and how a container image built by
+// osbuild should be pushed once the compose finishes. Credentials are never
+// logged or persisted in job results.
+type OCIRegistryTargetOptions struct {
+	// Registry is the hostname (and optional port) of the v2 registry, e.g.
+	// "quay.io" or "registry.example.com:5000".
+	Registry string `json:"registry"`
+	// Repository is the repository path within the registry, e.g.
+	// "osbuild/edge-container".
+	Repository string `json:"repository"`
+	// Tag is the tag to push the manifest under. Defaults to "latest" if empty.
+	Tag string `json:"tag"`
+
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// CosignKey, when set, is used to sign the pushed manifest digest with
+	// cosign's simple-signing format before the job reports success.
+	CosignKey string `json:"cosign_key,omitempty"`
+
+	// MediaType overrides the manifest media type osbuild would otherwise
+	// infer from the produced artifact, e.g. for registries that reject the
+	// OCI media type and require the older Docker one.
+	MediaType string `json:"media_type,omitempty"`
+}
+
+func (*OCIRegistryTargetOptions) isTargetOptions() {}
+
+// OCIRegistryTargetResultOptions is reported back once the uploader has
+// pushed the manifest, so the API can surface the resulting digests.
+type OCIRegistryTargetResultOptions struct {
+	Registry       string `json:"registry"`
+	Repository     string `json:"repository"`
+	Tag            string `json:"tag"`
+	Digest         string `json:"digest"`
+	ManifestDigest string `json:"manifest_digest"`
+	// MediaType is the manifest media type that was actually pushed,
+	// either inferred by osbuild or overridden via
+	// OCIRegistryTargetOptions.MediaType.
+	MediaType string `json:"media_type,omitempty"`
+}
+
+func (*OCIRegistryTargetResultOptions) isTargetResultOptions() {}
+
+func NewOCIRegistryTarget(options *OCIRegistryTargetOptions) *Target {
+	return &Target{
+		Name:    "org.osbuild.oci-registry",
+		Options: options,
+	}
+}
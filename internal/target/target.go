@@ -0,0 +1,85 @@
+package target
+
+import "github.com/google/uuid"
+
+// Target describes one destination an image should end up at once an
+// OSBuild job finishes: a cloud AMI, a GCP image, a storage bucket object,
+// a container registry tag, and so on. A single job can carry more than one
+// Target.
+type Target struct {
+	Uuid      uuid.UUID `json:"uuid"`
+	ImageName string    `json:"image_name"`
+	Name      string    `json:"name"`
+	Options   TargetOptions
+}
+
+// TargetOptions is implemented by every target-specific options type
+// (AWSTargetOptions, GCPTargetOptions, OCIRegistryTargetOptions, ...). It
+// exists so callers like the uploaders package can hand a parsed options
+// value back to the compose handler without depending on any one target's
+// concrete type.
+type TargetOptions interface {
+	isTargetOptions()
+}
+
+// TargetResult is what a worker reports back for a single Target once its
+// upload (or failure to upload) is done. A job with several Targets
+// produces one TargetResult per Target, so that one destination failing
+// doesn't have to take down the others' results with it.
+type TargetResult struct {
+	Name    string              `json:"name"`
+	Options TargetResultOptions `json:"options"`
+	// TargetError is set when this specific target failed, independently
+	// of whether the overall job succeeded.
+	TargetError *TargetError `json:"target_error,omitempty"`
+
+	// SignatureURL and SBOMURL are filled in by a successful SignJob run
+	// against this target, once compose-level signing/SBOM attachment has
+	// completed. Empty until then, and always empty if signing wasn't
+	// requested for the compose.
+	SignatureURL string `json:"signature_url,omitempty"`
+	SBOMURL      string `json:"sbom_url,omitempty"`
+
+	// BytesUploaded and TotalBytes track a still-running upload to this
+	// target (S3 multipart, Azure page blob, GCP resumable upload, ...),
+	// so a client polling the compose status can render real progress
+	// instead of a spinner. Both are 0 once the target isn't uploading,
+	// whether that's because it hasn't started yet or because it's done.
+	BytesUploaded int64 `json:"bytes_uploaded,omitempty"`
+	TotalBytes    int64 `json:"total_bytes,omitempty"`
+}
+
+// PercentComplete returns how far BytesUploaded/TotalBytes has progressed,
+// or 0 if TotalBytes isn't known yet.
+func (r *TargetResult) PercentComplete() float64 {
+	if r.TotalBytes == 0 {
+		return 0
+	}
+	return 100 * float64(r.BytesUploaded) / float64(r.TotalBytes)
+}
+
+// TargetError carries enough detail about a single target's failure for
+// the compose API to surface it without failing the whole compose.
+type TargetError struct {
+	Reason string `json:"reason"`
+}
+
+func (e *TargetError) Error() string {
+	return e.Reason
+}
+
+// TargetResultOptions is implemented by every target-specific result
+// options type (AWSTargetResultOptions, OCIRegistryTargetResultOptions,
+// ...).
+type TargetResultOptions interface {
+	isTargetResultOptions()
+}
+
+// NewTargetResult wraps options for the given target name into a
+// successful TargetResult.
+func NewTargetResult(name string, options TargetResultOptions) *TargetResult {
+	return &TargetResult{
+		Name:    name,
+		Options: options,
+	}
+}
@@ -0,0 +1,66 @@
+// Package aws implements uploaders.Uploader for the AWS EC2 AMI targets
+// (ami, ec2, ec2-ha, ec2-sap).
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+type Uploader struct {
+	// Bucket is the S3 bucket used to stage the image before it's
+	// imported as an AMI.
+	Bucket string
+}
+
+func New(bucket string) *Uploader {
+	return &Uploader{Bucket: bucket}
+}
+
+func (*Uploader) Name() string {
+	return "aws"
+}
+
+func (*Uploader) SupportedImageTypes() []string {
+	return []string{"aws", "aws-rhui", "aws-ha-rhui", "aws-sap-rhui"}
+}
+
+// UploadOptions is the wire format of the AWS upload options, decoupled
+// from the cloudapi-generated AWSEC2UploadOptions type.
+type UploadOptions struct {
+	Region            string    `json:"region"`
+	SnapshotName      *string   `json:"snapshot_name,omitempty"`
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+}
+
+func (u *Uploader) NewTarget(filename string, raw json.RawMessage) (*target.Target, error) {
+	var opts UploadOptions
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return nil, fmt.Errorf("aws: parsing upload options: %w", err)
+	}
+
+	// For service maintenance, images are discovered by the
+	// "Name:composer-api-*" tag filter. Currently all image names in the
+	// service are generated, so they're guaranteed to be unique as well.
+	// If users are ever allowed to name their images, an extra tag should
+	// be added.
+	key := fmt.Sprintf("composer-api-%s", uuid.New().String())
+	t := target.NewAWSTarget(&target.AWSTargetOptions{
+		Filename:          filename,
+		Region:            opts.Region,
+		Bucket:            u.Bucket,
+		Key:               key,
+		ShareWithAccounts: opts.ShareWithAccounts,
+	})
+	if opts.SnapshotName != nil {
+		t.ImageName = *opts.SnapshotName
+	} else {
+		t.ImageName = key
+	}
+
+	return t, nil
+}
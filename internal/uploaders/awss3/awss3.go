@@ -0,0 +1,53 @@
+// Package awss3 implements uploaders.Uploader for image types that are
+// simply dropped into an S3 bucket for the caller to fetch themselves
+// (guest-image, vsphere, image-installer, edge-installer, edge-commit).
+package awss3
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+type Uploader struct {
+	Bucket string
+}
+
+func New(bucket string) *Uploader {
+	return &Uploader{Bucket: bucket}
+}
+
+func (*Uploader) Name() string {
+	return "aws-s3"
+}
+
+func (*Uploader) SupportedImageTypes() []string {
+	return []string{"guest-image", "vsphere", "image-installer", "edge-installer", "edge-commit"}
+}
+
+// UploadOptions is the wire format of the AWS S3 upload options, decoupled
+// from the cloudapi-generated AWSS3UploadOptions type.
+type UploadOptions struct {
+	Region string `json:"region"`
+}
+
+func (u *Uploader) NewTarget(filename string, raw json.RawMessage) (*target.Target, error) {
+	var opts UploadOptions
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return nil, fmt.Errorf("aws-s3: parsing upload options: %w", err)
+	}
+
+	key := fmt.Sprintf("composer-api-%s", uuid.New().String())
+	t := target.NewAWSS3Target(&target.AWSS3TargetOptions{
+		Filename: filename,
+		Region:   opts.Region,
+		Bucket:   u.Bucket,
+		Key:      key,
+	})
+	t.ImageName = key
+
+	return t, nil
+}
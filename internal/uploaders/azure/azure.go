@@ -0,0 +1,60 @@
+// Package azure implements uploaders.Uploader for the Azure managed-image
+// target.
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+type Uploader struct{}
+
+func New() *Uploader {
+	return &Uploader{}
+}
+
+func (*Uploader) Name() string {
+	return "azure"
+}
+
+func (*Uploader) SupportedImageTypes() []string {
+	return []string{"azure"}
+}
+
+// UploadOptions is the wire format of the Azure upload options, decoupled
+// from the cloudapi-generated AzureUploadOptions type.
+type UploadOptions struct {
+	TenantId       string  `json:"tenant_id"`
+	Location       string  `json:"location"`
+	SubscriptionId string  `json:"subscription_id"`
+	ResourceGroup  string  `json:"resource_group"`
+	ImageName      *string `json:"image_name,omitempty"`
+}
+
+func (u *Uploader) NewTarget(filename string, raw json.RawMessage) (*target.Target, error) {
+	var opts UploadOptions
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return nil, fmt.Errorf("azure: parsing upload options: %w", err)
+	}
+
+	t := target.NewAzureImageTarget(&target.AzureImageTargetOptions{
+		Filename:       filename,
+		TenantID:       opts.TenantId,
+		Location:       opts.Location,
+		SubscriptionID: opts.SubscriptionId,
+		ResourceGroup:  opts.ResourceGroup,
+	})
+
+	if opts.ImageName != nil {
+		t.ImageName = *opts.ImageName
+	} else {
+		// if ImageName wasn't given, generate a random one
+		t.ImageName = fmt.Sprintf("composer-api-%s", uuid.New().String())
+	}
+
+	return t, nil
+}
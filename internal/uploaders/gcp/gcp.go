@@ -0,0 +1,64 @@
+// Package gcp implements uploaders.Uploader for the GCP image target.
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+type Uploader struct{}
+
+func New() *Uploader {
+	return &Uploader{}
+}
+
+func (*Uploader) Name() string {
+	return "gcp"
+}
+
+func (*Uploader) SupportedImageTypes() []string {
+	return []string{"gcp"}
+}
+
+// UploadOptions is the wire format of the GCP upload options, decoupled
+// from the cloudapi-generated GCPUploadOptions type.
+type UploadOptions struct {
+	Region            string    `json:"region"`
+	Bucket            string    `json:"bucket"`
+	ImageName         *string   `json:"image_name,omitempty"`
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+}
+
+func (u *Uploader) NewTarget(filename string, raw json.RawMessage) (*target.Target, error) {
+	var opts UploadOptions
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return nil, fmt.Errorf("gcp: parsing upload options: %w", err)
+	}
+
+	var share []string
+	if opts.ShareWithAccounts != nil {
+		share = *opts.ShareWithAccounts
+	}
+
+	object := fmt.Sprintf("composer-api-%s", uuid.New().String())
+	t := target.NewGCPTarget(&target.GCPTargetOptions{
+		Filename:          filename,
+		Region:            opts.Region,
+		Os:                "", // not exposed in cloudapi for now
+		Bucket:            opts.Bucket,
+		Object:            object,
+		ShareWithAccounts: share,
+	})
+	// Import will fail if an image with this name already exists
+	if opts.ImageName != nil {
+		t.ImageName = *opts.ImageName
+	} else {
+		t.ImageName = object
+	}
+
+	return t, nil
+}
@@ -0,0 +1,66 @@
+// Package ociregistry implements uploaders.Uploader for pushing
+// container-producing image types (rhel-edge-container and friends) to an
+// OCI/Docker v2 registry.
+package ociregistry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+type Uploader struct{}
+
+func New() *Uploader {
+	return &Uploader{}
+}
+
+func (*Uploader) Name() string {
+	return "oci-registry"
+}
+
+func (*Uploader) SupportedImageTypes() []string {
+	return []string{"edge-container"}
+}
+
+// UploadOptions is the wire format of the OCI registry upload options.
+type UploadOptions struct {
+	Registry   string  `json:"registry"`
+	Repository string  `json:"repository"`
+	Tag        string  `json:"tag,omitempty"`
+	Username   string  `json:"username,omitempty"`
+	Password   string  `json:"password,omitempty"`
+	CosignKey  *string `json:"cosign_key,omitempty"`
+	MediaType  *string `json:"media_type,omitempty"`
+}
+
+func (u *Uploader) NewTarget(filename string, raw json.RawMessage) (*target.Target, error) {
+	var opts UploadOptions
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return nil, fmt.Errorf("oci-registry: parsing upload options: %w", err)
+	}
+
+	tag := opts.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+	targetOptions := &target.OCIRegistryTargetOptions{
+		Registry:   opts.Registry,
+		Repository: opts.Repository,
+		Tag:        tag,
+		Username:   opts.Username,
+		Password:   opts.Password,
+	}
+	if opts.CosignKey != nil {
+		targetOptions.CosignKey = *opts.CosignKey
+	}
+	if opts.MediaType != nil {
+		targetOptions.MediaType = *opts.MediaType
+	}
+
+	t := target.NewOCIRegistryTarget(targetOptions)
+	t.ImageName = fmt.Sprintf("%s/%s:%s", opts.Registry, opts.Repository, tag)
+
+	return t, nil
+}
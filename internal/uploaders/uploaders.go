@@ -0,0 +1,83 @@
+// Package uploaders decouples the cloud-upload logic historically embedded
+// in the `internal/cloudapi/v2` PostCompose handler into small,
+// independently testable per-destination packages.
+//
+// Each destination (AWS, AWS S3, GCP, Azure, an OCI registry, ...)
+// implements Uploader and registers itself for the image type identifiers
+// it knows how to produce a target.Target for. PostCompose then only needs
+// to look the right Uploader up by image type and hand it the raw
+// UploadOptions JSON, instead of growing a new switch-case for every
+// destination that gets added.
+package uploaders
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+// Uploader parses the upload options for one or more image types and turns
+// them into a target.Target that can be attached to an OSBuild job.
+//
+// ParseOptions and NewTarget are deliberately one call: several
+// destinations (AWS's optional snapshot name, Azure's optional image name)
+// decide the target's ImageName from a raw-options field that has no home
+// on the parsed target.TargetOptions, so splitting "parse" from "build"
+// would just mean threading that raw value through a second call anyway.
+type Uploader interface {
+	// Name identifies the uploader, e.g. "aws", "gcp", "oci-registry".
+	Name() string
+
+	// SupportedImageTypes lists the cloudapi ImageTypes (as their wire
+	// string value) this uploader accepts UploadOptions for.
+	SupportedImageTypes() []string
+
+	// NewTarget unmarshals raw upload options and builds the target.Target
+	// to attach to the OSBuild job for an image with the given filename.
+	NewTarget(filename string, raw json.RawMessage) (*target.Target, error)
+}
+
+// Registry maps an image type identifier, or an uploader's own Name(), to
+// the Uploader responsible for it.
+type Registry struct {
+	byImageType map[string]Uploader
+	byName      map[string]Uploader
+}
+
+// NewRegistry builds a Registry from a set of uploaders, indexing each by
+// every image type it declares support for. Registering two uploaders for
+// the same image type is a programming error and panics, same as
+// registering the same HTTP route twice would.
+func NewRegistry(uploaders ...Uploader) *Registry {
+	r := &Registry{
+		byImageType: make(map[string]Uploader),
+		byName:      make(map[string]Uploader),
+	}
+	for _, u := range uploaders {
+		r.byName[u.Name()] = u
+		for _, it := range u.SupportedImageTypes() {
+			if existing, ok := r.byImageType[it]; ok {
+				panic(fmt.Sprintf("uploaders: image type %q already registered to %q", it, existing.Name()))
+			}
+			r.byImageType[it] = u
+		}
+	}
+	return r
+}
+
+// For returns the Uploader registered for imageType, if any. Used for the
+// single-UploadOptions compose request, where the upload destination is
+// implied by the image type being built.
+func (r *Registry) For(imageType string) (Uploader, bool) {
+	u, ok := r.byImageType[imageType]
+	return u, ok
+}
+
+// ByName returns the Uploader with the given Name(), if any. Used for the
+// multi-target UploadTargets compose request, where each entry names its
+// destination explicitly instead of relying on the image type.
+func (r *Registry) ByName(name string) (Uploader, bool) {
+	u, ok := r.byName[name]
+	return u, ok
+}
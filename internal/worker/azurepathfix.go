@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// jobTypeAzurePathFix is the job type string AzurePathFixJobs are enqueued
+// and dequeued under.
+const jobTypeAzurePathFix = "azure-pathfix"
+
+// AzurePathFixJob performs the well-known Azure VHD page-blob fixups after
+// a successful "org.osbuild.azure.image" upload: verifying the blob is a
+// fixed-size, 512-byte-aligned VHD; converting it from a block blob to a
+// page blob (streaming through a temporary staging container) if it
+// wasn't uploaded as one already; and setting the metadata/tags Azure
+// Compute Gallery / Managed Image ingestion expects. It's chained after
+// the OSBuildJob that performed the upload, the same way a SignJob is.
+type AzurePathFixJob struct {
+	// TargetName identifies which of the dependency OSBuildJob's Targets
+	// to fix up; the blob URL itself is only known once that job has
+	// actually uploaded, so it's read from the dependency's TargetResult
+	// rather than carried here.
+	TargetName string `json:"target_name"`
+
+	// StagingContainer is a container in the same storage account used to
+	// stage the page-blob conversion, since a blob can't be rewritten as
+	// a different blob type in place.
+	StagingContainer string `json:"staging_container"`
+}
+
+// AzurePathFixJobResult is reported back once the fixup has run, or
+// failed. Applied is true even when the blob was already a correctly
+// formed page blob and nothing needed converting.
+type AzurePathFixJobResult struct {
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
+
+	Applied        bool   `json:"applied"`
+	PreFixBlobURL  string `json:"pre_fix_blob_url,omitempty"`
+	PostFixBlobURL string `json:"post_fix_blob_url,omitempty"`
+}
+
+// EnqueueAzurePathFixAsDependency enqueues an AzurePathFixJob depending on
+// the given jobs — typically the OSBuildJob whose Azure upload it fixes up —
+// the same way EnqueueOSBuildAsDependency chains an OSBuildJob after its
+// ManifestJobByID. Returns the id of the new job.
+func (s *Server) EnqueueAzurePathFixAsDependency(job *AzurePathFixJob, dependencies ...uuid.UUID) (uuid.UUID, error) {
+	return s.jobs.Enqueue(jobTypeAzurePathFix, job, dependencies, nil, 0, "")
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// WaitForPending returns a channel that is closed once jobID becomes
+// pending (i.e. every job it depends on has finished) or ctx is canceled.
+// It's backed by the underlying jobqueue.JobQueue's Notify, so callers such
+// as generateManifest no longer have to poll RequestJobById in a sleep loop
+// while a compose's depsolve job is still running.
+func (s *Server) WaitForPending(ctx context.Context, jobID uuid.UUID) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-s.jobs.Notify(jobID):
+		case <-ctx.Done():
+		}
+	}()
+	return done
+}
@@ -0,0 +1,27 @@
+package worker
+
+// Phase names the step an in-flight OSBuild job is currently in. Workers
+// update OSBuildJobResult.Progress as they move through these so the
+// compose API can report more than a single "still building" spinner for
+// the whole job.
+type Phase string
+
+const (
+	PhaseManifestGenerating Phase = "manifest-generating"
+	PhaseOSBuildRunning     Phase = "osbuild-running"
+	PhaseUploading          Phase = "uploading"
+	PhaseRegistering        Phase = "registering"
+	PhaseFinalizing         Phase = "finalizing"
+)
+
+// Progress is a heartbeat a worker writes to OSBuildJobResult (and
+// OSBuildKojiJobResult) while a job is still running.
+type Progress struct {
+	Phase Phase `json:"phase,omitempty"`
+
+	// PercentComplete is only meaningful while Phase is PhaseUploading:
+	// it tracks a large transfer (S3 multipart, Azure page blob, GCP
+	// resumable upload) that doesn't otherwise have an observable
+	// milestone between "started" and "done".
+	PercentComplete float64 `json:"percent_complete,omitempty"`
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// jobTypeSign is the job type string SignJobs are enqueued and dequeued
+// under.
+const jobTypeSign = "sign"
+
+// SigningOptions configures how a SignJob signs an image digest: either
+// with a locally held cosign private key, or "keyless" via Fulcio/Rekor.
+// It's filled in from the compose request's Customizations.Signing block.
+type SigningOptions struct {
+	// KeyRef is a cosign private-key reference (a path, a KMS URI, ...).
+	// Empty for keyless signing.
+	KeyRef string `json:"key_ref,omitempty"`
+
+	// RekorURL is the transparency log the signature (and, in keyless
+	// mode, the Fulcio certificate) is uploaded to.
+	RekorURL string `json:"rekor_url,omitempty"`
+
+	// FulcioURL issues the short-lived signing certificate for keyless
+	// signing. Empty when KeyRef is set.
+	FulcioURL string `json:"fulcio_url,omitempty"`
+}
+
+// SignJob signs the image already uploaded by a finished OSBuildJob and,
+// optionally, attaches an SBOM for it. It depends on that OSBuildJob (so it
+// only runs once the target's upload has completed and the final object
+// key or registry digest is known) and, when SBOMFormat is set, also on
+// the compose's DepsolveJob: the worker receives both dependencies' results
+// as dynamic arguments, the same way the manifest job receives its
+// DepsolveJobResult, rather than having PackageSpecs duplicated here.
+type SignJob struct {
+	// TargetName identifies which of the OSBuildJob's Targets to sign
+	// (e.g. "org.osbuild.oci-registry"), since a multi-target compose may
+	// only want some destinations signed.
+	TargetName string `json:"target_name"`
+
+	Signing SigningOptions `json:"signing"`
+
+	// SBOMFormat, if non-empty, generates an SBOM in this format from the
+	// compose's depsolved package set and attaches it alongside the
+	// signature.
+	SBOMFormat string `json:"sbom_format,omitempty"`
+}
+
+// SignJobResult is reported back once the signature (and SBOM, if
+// requested) have been produced and uploaded as sibling artifacts next to
+// the image: for S3/GCP as `<key>.sig` / `<key>.sbom.json` objects, for OCI
+// registries as referrers of the image manifest per the OCI 1.1 Referrers
+// API.
+type SignJobResult struct {
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
+
+	SignatureURL string `json:"signature_url,omitempty"`
+	SBOMURL      string `json:"sbom_url,omitempty"`
+}
+
+// EnqueueSignAsDependency enqueues a SignJob depending on the given jobs —
+// typically the OSBuildJob it signs and, when job.SBOMFormat is set, the
+// compose's DepsolveJob too — the same way EnqueueOSBuildAsDependency chains
+// an OSBuildJob after its ManifestJobByID. Returns the id of the new job.
+func (s *Server) EnqueueSignAsDependency(job *SignJob, dependencies ...uuid.UUID) (uuid.UUID, error) {
+	return s.jobs.Enqueue(jobTypeSign, job, dependencies, nil, 0, "")
+}